@@ -329,6 +329,26 @@ func BenchmarkReader_Read_LongFields(b *testing.B) {
 	}
 }
 
+// BenchmarkReader_Read_LongUnquotedFields benchmarks reading CSV with long unquoted fields
+func BenchmarkReader_Read_LongUnquotedFields(b *testing.B) {
+	longField := strings.Repeat("a", 1000)
+	data := strings.Repeat(longField+","+longField+","+longField+"\n", 100)
+
+	for b.Loop() {
+		reader := NewReader(bufio.NewReader(strings.NewReader(data)))
+
+		for {
+			_, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
 // BenchmarkReader_Read_MultilineFields benchmarks reading CSV with multiline fields
 func BenchmarkReader_Read_MultilineFields(b *testing.B) {
 	multilineField := "line1\nline2\nline3"
@@ -350,3 +370,218 @@ func BenchmarkReader_Read_MultilineFields(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkReader_Read_MultiByteDelimiter benchmarks reading CSV with a multi-byte delimiter
+func BenchmarkReader_Read_MultiByteDelimiter(b *testing.B) {
+	data := generateCSVData(1000, 10, false)
+	data = strings.ReplaceAll(data, ",", "||") // Replace commas with double pipes
+
+	for b.Loop() {
+		reader := NewReader(bufio.NewReader(strings.NewReader(data)))
+		reader.Delimiter = "||"
+
+		for {
+			_, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkReader_Read_ReuseRecord benchmarks reading medium simple CSV with ReuseRecord
+func BenchmarkReader_Read_ReuseRecord(b *testing.B) {
+	data := generateCSVData(1000, 10, false)
+
+	for b.Loop() {
+		reader := NewReader(bufio.NewReader(strings.NewReader(data)))
+		reader.ReuseRecord = true
+
+		for {
+			_, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkReader_ReadBytes_MediumSimple benchmarks zero-copy reading of medium simple CSV
+func BenchmarkReader_ReadBytes_MediumSimple(b *testing.B) {
+	data := generateCSVData(1000, 10, false)
+
+	for b.Loop() {
+		reader := NewReader(bufio.NewReader(strings.NewReader(data)))
+
+		for {
+			_, err := reader.ReadBytes()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkReader_ReadBytes_MediumQuoted benchmarks zero-copy reading of medium quoted CSV
+func BenchmarkReader_ReadBytes_MediumQuoted(b *testing.B) {
+	data := generateCSVData(1000, 10, true)
+
+	for b.Loop() {
+		reader := NewReader(bufio.NewReader(strings.NewReader(data)))
+
+		for {
+			_, err := reader.ReadBytes()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkReader_ReadBytes_LargeSimple benchmarks zero-copy reading of large simple CSV
+func BenchmarkReader_ReadBytes_LargeSimple(b *testing.B) {
+	data := generateCSVData(10000, 20, false)
+
+	for b.Loop() {
+		reader := NewReader(bufio.NewReader(strings.NewReader(data)))
+
+		for {
+			_, err := reader.ReadBytes()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// tablePrefix is the byte by byte scan plainPrefix replaced, kept out of
+// line like plainPrefix so both pay for a call
+//
+//go:noinline
+func tablePrefix(special *[256]bool, buf []byte) int {
+	i := 0
+	for i < len(buf) && !special[buf[i]] {
+		i++
+	}
+	return i
+}
+
+// benchmarkScan measures finding every delimiter in a buffer of fields of
+// the given length, either with the reader's scanner or with the byte by
+// byte table lookup it replaced
+func benchmarkScan(b *testing.B, fieldLen int, swar bool) {
+	buf := []byte(strings.Repeat(strings.Repeat("x", fieldLen)+",", 64*1024/(fieldLen+1)))
+	reader := NewReader(bufio.NewReader(strings.NewReader("")))
+	reader.setSpecial(',', '"', 0, '\n')
+	b.SetBytes(int64(len(buf)))
+
+	for b.Loop() {
+		for pos := 0; pos < len(buf); pos++ {
+			if swar {
+				pos += reader.plainPrefix(buf[pos:])
+			} else {
+				pos += tablePrefix(&reader.special, buf[pos:])
+			}
+		}
+	}
+}
+
+// BenchmarkScan_Table_Field4 benchmarks the lookup table scan on 4-byte fields
+func BenchmarkScan_Table_Field4(b *testing.B) { benchmarkScan(b, 4, false) }
+
+// BenchmarkScan_SWAR_Field4 benchmarks the SWAR scan on 4-byte fields
+func BenchmarkScan_SWAR_Field4(b *testing.B) { benchmarkScan(b, 4, true) }
+
+// BenchmarkScan_Table_Field16 benchmarks the lookup table scan on 16-byte fields
+func BenchmarkScan_Table_Field16(b *testing.B) { benchmarkScan(b, 16, false) }
+
+// BenchmarkScan_SWAR_Field16 benchmarks the SWAR scan on 16-byte fields
+func BenchmarkScan_SWAR_Field16(b *testing.B) { benchmarkScan(b, 16, true) }
+
+// BenchmarkScan_Table_Field256 benchmarks the lookup table scan on 256-byte fields
+func BenchmarkScan_Table_Field256(b *testing.B) { benchmarkScan(b, 256, false) }
+
+// BenchmarkScan_SWAR_Field256 benchmarks the SWAR scan on 256-byte fields
+func BenchmarkScan_SWAR_Field256(b *testing.B) { benchmarkScan(b, 256, true) }
+
+// BenchmarkReader_Read_ManyBlobs_NewReader benchmarks parsing many small inputs with a new Reader each
+func BenchmarkReader_Read_ManyBlobs_NewReader(b *testing.B) {
+	data := generateCSVData(5, 5, false)
+
+	for b.Loop() {
+		for range 100 {
+			reader := NewReaderFrom(strings.NewReader(data))
+			for {
+				if _, err := reader.Read(); err != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkReader_Read_ManyBlobs_Reset benchmarks parsing many small inputs with one Reset Reader
+func BenchmarkReader_Read_ManyBlobs_Reset(b *testing.B) {
+	data := generateCSVData(5, 5, false)
+	src := strings.NewReader(data)
+	reader := NewReaderFrom(src)
+
+	for b.Loop() {
+		for range 100 {
+			src.Reset(data)
+			reader.Reset(src)
+			for {
+				if _, err := reader.Read(); err != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// benchmarkEncoding reads the same records encoded with enc
+func benchmarkEncoding(b *testing.B, enc Encoding) {
+	text := strings.Repeat("Иван,Петров,\"Москва, Россия\",42\n", 1000)
+	data := text
+	switch enc {
+	case EncodingUTF16LE:
+		data = encodeUTF16(text, false, true)
+	case EncodingWindows1251:
+		data = encodeSingleByte(text, enc)
+	}
+	b.SetBytes(int64(len(data)))
+
+	for b.Loop() {
+		reader := NewReader(bufio.NewReader(strings.NewReader(data)))
+		reader.Encoding = enc
+
+		for {
+			_, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkReader_Read_EncodingUTF8(b *testing.B)        { benchmarkEncoding(b, EncodingUTF8) }
+func BenchmarkReader_Read_EncodingUTF16LE(b *testing.B)     { benchmarkEncoding(b, EncodingUTF16LE) }
+func BenchmarkReader_Read_EncodingWindows1251(b *testing.B) { benchmarkEncoding(b, EncodingWindows1251) }
@@ -542,3 +542,42 @@ func BenchmarkComparison_MultilineFields_GoBuiltin(b *testing.B) {
 		}
 	}
 }
+
+// Benchmark: Medium Simple CSV with ReuseRecord - CSVC vs Go Built-in
+func BenchmarkComparison_ReuseRecord_CSVC(b *testing.B) {
+	data := generateCSVDataForComparison(1000, 10, false)
+
+	for b.Loop() {
+		reader := NewReader(bufio.NewReader(strings.NewReader(data)))
+		reader.ReuseRecord = true
+
+		for {
+			_, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkComparison_ReuseRecord_GoBuiltin(b *testing.B) {
+	data := generateCSVDataForComparison(1000, 10, false)
+
+	for b.Loop() {
+		reader := csv.NewReader(strings.NewReader(data))
+		reader.ReuseRecord = true
+
+		for {
+			_, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -2,7 +2,11 @@ package csvc
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
 )
 
 // Constants for CSV parsing
@@ -12,109 +16,1211 @@ const (
 	ASCII_LF    = '\n' // Line feed character
 	ASCII_CR    = '\r' // Carriage return character
 	ASCII_TAB   = '\t' // Tab character
+	ASCII_RS    = 0x1E // Record separator character
+	ASCII_US    = 0x1F // Unit separator character
 )
 
+// Errors wrapped by ParseError in Strict mode
+var (
+	ErrBareQuote = errors.New("bare \" in non-quoted field")
+	ErrQuote     = errors.New("extraneous or missing \" in quoted field")
+)
+
+var (
+	// ErrUnterminatedQuote reports input that ends inside a quoted field,
+	// as for a truncated file, in every quote mode (libcsv's
+	// CSV_STRICT_FINI). The ParseError points at the opening quote.
+	ErrUnterminatedQuote = errors.New("unterminated quoted field at end of input")
+)
+
+// ErrFieldCount is wrapped by the ParseError returned for a record whose
+// number of fields does not match FieldsPerRecord.
+var ErrFieldCount = errors.New("wrong number of fields")
+
+// ErrBlankLine is wrapped by the ParseError returned for a blank line
+// when BlankLines is BlankError.
+var ErrBlankLine = errors.New("blank line")
+
+// ErrTooLarge is wrapped by the ParseError returned when a record exceeds
+// MaxFieldBytes, MaxRecordBytes or MaxFields, like libcsv's CSV_ETOOBIG.
+// The ParseError points at the start of the offending field.
+var ErrTooLarge = errors.New("field or record too large")
+
+// Errors returned by Read for an invalid Reader configuration
+var (
+	ErrInvalidDelimiter = errors.New("invalid field delimiter")
+	ErrInvalidQuote     = errors.New("invalid quote character")
+	ErrInvalidComment   = errors.New("invalid comment character")
+
+	ErrInvalidTerminator = errors.New("invalid record terminator")
+	ErrInvalidEscape     = errors.New("invalid escape character")
+	ErrInvalidQuoteMode  = errors.New("strict and lazy quotes modes are mutually exclusive")
+	ErrInvalidEncoding   = errors.New("invalid encoding")
+)
+
+// ErrUTF16 is returned by Read for input starting with a UTF-16 byte order
+// mark while Encoding is EncodingUTF8. BOM tells the byte order; set
+// Encoding to EncodingUTF16LE or EncodingUTF16BE to transcode the input.
+var ErrUTF16 = errors.New("input is UTF-16 encoded")
+
+// ParseError is returned for parsing errors.
+// Line and column numbers are 1-based, the byte offset is 0-based.
+type ParseError struct {
+	StartLine int   // Line where the record starts
+	Line      int   // Line where the error occurred
+	Column    int   // Column (1-based byte index) where the error occurred
+	Offset    int64 // Byte offset in the input where the error occurred
+	Err       error // The actual error
+}
+
+func (e *ParseError) Error() string {
+	if e.Err == ErrFieldCount && e.StartLine == e.Line {
+		return fmt.Sprintf("record on line %d, column %d (offset %d): %v",
+			e.StartLine, e.Column, e.Offset, e.Err)
+	}
+	if e.StartLine != e.Line {
+		return fmt.Sprintf("record on line %d; parse error on line %d, column %d (offset %d): %v",
+			e.StartLine, e.Line, e.Column, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("parse error on line %d, column %d (offset %d): %v",
+		e.Line, e.Column, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// RaggedPolicy selects how Read handles records whose number of fields
+// differs from FieldsPerRecord.
+type RaggedPolicy int
+
+const (
+	RaggedError    RaggedPolicy = iota // Return ErrFieldCount
+	RaggedPad                          // Pad short records with empty fields
+	RaggedTruncate                     // Drop the extra fields of long records
+	RaggedFit                          // Pad short and truncate long records
+)
+
+// BlankLinePolicy selects how Read handles blank lines, lines with no
+// bytes at all before their terminator.
+type BlankLinePolicy int
+
+const (
+	BlankKeep  BlankLinePolicy = iota // Return a record with one empty field
+	BlankSkip                         // Skip the line, like encoding/csv
+	BlankError                        // Return ErrBlankLine and skip the line
+)
+
+// Terminator selects the line breaks that end a record, like the is_term
+// callback of libcsv. Terminators inside quoted fields are field data.
+type Terminator int
+
+const (
+	TermDefault Terminator = iota // LF or CRLF; a lone CR is data
+	TermLF                        // LF only; CR is data
+	TermCRLF                      // CRLF only; lone CR and LF are data
+	TermCR                        // Lone CR only (classic Mac OS); LF is data
+	TermAny                       // LF, CRLF or lone CR
+	TermCustom                    // RecordSeparator only; CR and LF are data
+)
+
+// BOM identifies the byte order mark found at the start of the input
+type BOM int
+
+const (
+	BOMNone    BOM = iota // No byte order mark
+	BOMUTF8               // EF BB BF, as written by Excel
+	BOMUTF16LE            // FF FE
+	BOMUTF16BE            // FE FF
+)
+
+// bomUTF8 is the UTF-8 encoded byte order mark
+const bomUTF8 = "\xEF\xBB\xBF"
+
 // Reader represents a CSV reader
 type Reader struct {
 	Comma byte
 
-	r        *bufio.Reader
-	fieldBuf []byte // reusable buffer for building fields
+	// Delimiter, if non-empty, overrides Comma with a delimiter of any
+	// length, such as "||" or a UTF-8 encoded rune like "¦". It must not
+	// contain the quote character or line breaks. Single-byte delimiters
+	// are parsed as fast as Comma.
+	Delimiter string
+
+	// Quote encloses fields containing delimiters, quotes or line breaks
+	// (default: ASCII_DQ). A quote inside a quoted field is escaped by
+	// doubling it. The zero value disables quoting, so quote characters
+	// are treated as plain data.
+	Quote byte
+
+	// Strict rejects input that violates RFC 4180: a quote inside a
+	// non-quoted field returns ErrBareQuote, and anything other than a
+	// delimiter or line break after a closing quote returns ErrQuote.
+	// Input ending inside a quoted field returns ErrUnterminatedQuote
+	// whether or not Strict is set.
+	Strict bool
+
+	// LazyQuotes accepts stray quotes as data instead of toggling quoting
+	// wherever they appear. A quote only opens a quoted field at the start
+	// of the field, so a bare quote in a non-quoted field is literal. Inside
+	// a quoted field, a quote followed by another quote is an escaped
+	// quote, a quote followed by a delimiter, a line break or EOF closes
+	// the field, and any other quote is literal. LazyQuotes and Strict
+	// are mutually exclusive; with neither set, every quote outside a
+	// quoted field opens one.
+	LazyQuotes bool
+
+	// Comment, if not 0, is the comment character. Lines beginning with
+	// it are skipped, unless they continue a quoted field. It must differ
+	// from the delimiter and the quote character.
+	Comment byte
+
+	// SkipLines is the number of physical lines to skip before parsing
+	// the first record, e.g. a metadata preamble. Skipped lines are
+	// counted by Line, FieldPos and ParseError.
+	SkipLines int
+
+	// BlankLines is the policy for blank lines outside quoted fields
+	// (default: BlankKeep). Skipped lines are still counted by Line,
+	// FieldPos and ParseError. Lines holding only whitespace are not
+	// blank, even when the whitespace is trimmed.
+	BlankLines BlankLinePolicy
+
+	// KeepBOM leaves a leading byte order mark in the first field instead
+	// of stripping it. It is reported by BOM either way.
+	KeepBOM bool
+
+	// Encoding is the character set of the input (default: EncodingUTF8).
+	// Other encodings are transcoded to UTF-8 before parsing, so Comma,
+	// Quote and the other special bytes are matched against the decoded
+	// text, and all fields are returned as UTF-8. InputOffset, columns and
+	// the size limits then count UTF-8 bytes. With a UTF-16 encoding, a
+	// byte order mark at the start of the input overrides the byte order.
+	// Encoding is read by the first Read after NewReader or Reset.
+	Encoding Encoding
+
+	// TrimLeadingSpace and TrimTrailingSpace remove whitespace around
+	// fields, modeled on libcsv: leading whitespace before a field or its
+	// opening quote, and trailing whitespace after a field or between its
+	// closing quote and the delimiter. Whitespace inside quotes is kept.
+	// In Strict mode, whitespace around a quoted field is only accepted
+	// when it is trimmed.
+	TrimLeadingSpace  bool
+	TrimTrailingSpace bool
+
+	// Whitespace is the set of bytes trimmed by TrimLeadingSpace and
+	// TrimTrailingSpace. If empty, space and ASCII_TAB are used. The
+	// delimiter is never treated as whitespace, so tab-separated fields
+	// are not merged.
+	Whitespace string
+
+	// Escape, if not 0, is the escape character, typically '\\' for
+	// MySQL and Hive exports. Inside and outside quotes it makes the next
+	// byte literal, so escaped delimiters, quotes and line breaks are
+	// field data. The sequences \0, \b, \n, \r, \t and \Z decode to
+	// NUL, backspace, LF, CR, tab and Ctrl-Z, and an unquoted field that
+	// is exactly \N is the null marker reported by IsNull. Doubled quotes
+	// keep working; set Quote to 0 for formats that only use escapes.
+	Escape byte
+
+	// EmptyIsNull reports empty fields that were not quoted as null, like
+	// libcsv's CSV_EMPTY_IS_NULL: in a,,"" the second field is null and the
+	// third is an empty string. Null fields are still returned as "";
+	// check them with IsNull or use ReadRecord.
+	EmptyIsNull bool
+
+	// ReuseRecord controls whether calls to Read may return a slice sharing
+	// the backing array of the previous call's returned slice, avoiding a
+	// []string allocation per record. The strings themselves are never
+	// reused, so keep a record past the next Read by copying the slice.
+	ReuseRecord bool
+
+	// Terminator is the record terminator policy (default: TermDefault).
+	// Line numbers count the terminator bytes: LF, lone CRs with TermAny,
+	// CR with TermCR and RecordSeparator with TermCustom.
+	Terminator Terminator
+
+	// RecordSeparator is the record terminator used with TermCustom,
+	// e.g. ASCII_RS together with a Comma of ASCII_US.
+	RecordSeparator byte
+
+	// FieldsPerRecord is the number of expected fields per record.
+	// If positive, every record must have that many fields. If 0, it is
+	// set to the number of fields of the first record, so that all
+	// following records must match. If negative (the NewReader default),
+	// records may have a variable number of fields.
+	FieldsPerRecord int
+
+	// RaggedRows decides what happens to records not matching
+	// FieldsPerRecord. With RaggedError (the default) Read returns the
+	// record together with a ParseError wrapping ErrFieldCount. Records
+	// the policy cannot fix are reported the same way.
+	RaggedRows RaggedPolicy
+
+	// MaxFieldBytes, MaxRecordBytes and MaxFields bound the memory a
+	// single record can take, for untrusted input: the bytes of one
+	// field, the bytes of all fields of a record (excluding quotes,
+	// delimiters and the terminator) and the number of fields. Read fails
+	// with ErrTooLarge as soon as a limit is exceeded, without reading the
+	// rest of the record, so an unterminated quote cannot pull the whole
+	// input into memory. Zero means no limit. Parsing cannot continue
+	// meaningfully after ErrTooLarge, as the next Read starts in the
+	// middle of the oversized record.
+	MaxFieldBytes  int
+	MaxRecordBytes int
+	MaxFields      int
+
+	r          *bufio.Reader
+	bufferSize int      // input buffer size requested through WithBufferSize
+	ownsBuffer bool     // r was allocated by the Reader, so Reset may reuse it
+	autoFields bool     // FieldsPerRecord was 0 and set by the first record
+	bomChecked bool     // the start of the input was checked for a BOM
+	bom        BOM      // byte order mark found at the start of the input
+	recordBuf  []byte   // reusable buffer holding all fields of a record
+	fieldEnds  []int    // end index of each field in recordBuf
+	lastRecord []string // record slice kept for ReuseRecord
+	lastFields []Field  // record slice kept for ReuseRecord by ReadRecord
+	byteRecord [][]byte // record slice returned by ReadBytes
+
+	raw     *bufio.Reader // input before transcoding, when Encoding is set
+	dec     *decoder      // transcoder between raw and r
+	decoded *bufio.Reader // buffer over dec, reused across Reset
+
+	special    [256]bool   // bytes that stop a bulk scan
+	specialFor [4]byte     // comma, quote, escape and terminator of special
+	specialOK  bool        // special has been built
+	scan       swarScanner // word-at-a-time scanner for special
+
+	line    int   // current line number (1-based)
+	col     int   // bytes consumed on the current line
+	offset  int64 // bytes consumed from the input
+	newline byte  // byte advancing the line number
+
+	recordLine int         // line where the last returned record started
+	fields     []fieldInfo // position and flags of each field of the last record
+	parsing    []fieldInfo // fields of the record being read, swapped into fields once it is returned
+	recordEnd  fieldInfo   // position of the terminator or EOF ending the last record
 }
 
+// fieldInfo describes a field of the last record: where it starts, as a
+// 1-based line and column and a 0-based byte offset, and how it was parsed
+type fieldInfo struct {
+	line, col int
+	offset    int64
+	quoted    bool // the field contained a quoted part
+	null      bool // the field was the null marker or, with EmptyIsNull, empty and unquoted
+}
+
+// Field is a field returned by ReadRecord together with how it was
+// written in the input
+type Field struct {
+	Value string
+
+	// Quoted is set when the field contained a quoted part, so "" is an
+	// empty string rather than a missing value
+	Quoted bool
+
+	// Null is set for the fields reported by IsNull
+	Null bool
+}
+
+// NewReader creates a CSV reader reading from r with its buffer as is.
+// NewReaderFrom accepts any io.Reader and configuration options.
 func NewReader(r *bufio.Reader) *Reader {
 	return &Reader{
-		Comma:    ',',
-		r:        r,
-		fieldBuf: make([]byte, 0, 256),
+		Comma:           ',',
+		Quote:           ASCII_DQ,
+		FieldsPerRecord: -1,
+		r:               r,
+		recordBuf:       make([]byte, 0, 256),
+		line:            1,
+		newline:         ASCII_LF,
+	}
+}
+
+// Reset makes the reader read from r as if it were new, keeping its
+// configuration and the buffers grown by earlier records, so one Reader
+// can be pooled and reused across many inputs. A FieldsPerRecord of 0
+// set from the first record is cleared again. r is read through the
+// reader's own input buffer; a bufio.Reader passed to NewReader is not
+// reused, so the caller may keep using it.
+func (b *Reader) Reset(r io.Reader) {
+	if b.raw != nil {
+		// Drop the transcoder; the next Read sets it up again
+		b.r, b.raw = b.raw, nil
+	}
+	if b.ownsBuffer {
+		b.r.Reset(r)
+	} else {
+		size := b.bufferSize
+		if size == 0 {
+			size = DefaultBufferSize
+			if b.r != nil {
+				size = b.r.Size()
+			}
+		}
+		b.r = bufio.NewReaderSize(r, size)
+		b.ownsBuffer = true
+	}
+
+	if b.autoFields {
+		b.FieldsPerRecord, b.autoFields = 0, false
+	}
+	b.recordBuf = b.recordBuf[:0]
+	b.fieldEnds = b.fieldEnds[:0]
+	b.fields = b.fields[:0]
+	b.parsing = b.parsing[:0]
+	b.line, b.col, b.offset = 1, 0, 0
+	b.recordLine = 0
+	b.bomChecked, b.bom = false, BOMNone
+}
+
+// Line returns the line number on which the most recently returned record
+// started. Lines are counted from 1, including line breaks inside quoted
+// fields. Line returns 0 before the first record is read.
+func (b *Reader) Line() int {
+	return b.recordLine
+}
+
+// BOM returns the byte order mark found at the start of the input. It is
+// set by the first call to Read.
+func (b *Reader) BOM() BOM {
+	return b.bom
+}
+
+// InputOffset returns the input stream byte offset of the current reader
+// position. After a successful Read it is the offset of the end of the
+// returned record.
+func (b *Reader) InputOffset() int64 {
+	return b.offset
+}
+
+// FieldPos returns the line and column of the start of the field with
+// index field in the most recently returned record. For a quoted field the
+// position is that of the opening quote, and for a field added by
+// RaggedPad or RaggedFit it is the end of the record: its terminator, or
+// the end of the input. Numbering of lines and columns starts at 1;
+// columns are counted in bytes, not runes. A Read returning io.EOF or an
+// error other than ErrFieldCount returns no record and leaves the
+// positions, like IsNull, IsQuoted and Line, unchanged.
+//
+// If FieldPos is called with an out-of-bounds index, it panics.
+func (b *Reader) FieldPos(field int) (line, column int) {
+	if field < 0 || field >= len(b.fields) {
+		panic("out of range index passed to FieldPos")
+	}
+	p := &b.fields[field]
+	return p.line, p.col
+}
+
+// IsNull reports whether the field with index field in the most recently
+// returned record was the null marker, an unquoted field consisting of
+// Escape followed by 'N' (MySQL's \N), or with EmptyIsNull an empty field
+// that was not quoted. Null fields are returned as "".
+//
+// If IsNull is called with an out-of-bounds index, it panics.
+func (b *Reader) IsNull(field int) bool {
+	if field < 0 || field >= len(b.fields) {
+		panic("out of range index passed to IsNull")
+	}
+	return b.fields[field].null
+}
+
+// IsQuoted reports whether the field with index field in the most
+// recently returned record contained a quoted part. It tells a quoted
+// empty field ("") from a missing one.
+//
+// If IsQuoted is called with an out-of-bounds index, it panics.
+func (b *Reader) IsQuoted(field int) bool {
+	if field < 0 || field >= len(b.fields) {
+		panic("out of range index passed to IsQuoted")
+	}
+	return b.fields[field].quoted
+}
+
+// startField records the position of the field beginning at the next byte
+func (b *Reader) startField() {
+	b.parsing = append(b.parsing, fieldInfo{line: b.line, col: b.col + 1, offset: b.offset})
+}
+
+// readByte reads the next byte and advances the position counters
+func (b *Reader) readByte() (byte, error) {
+	ch, err := b.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	b.offset++
+	if ch == b.newline {
+		b.line++
+		b.col = 0
+	} else {
+		b.col++
+	}
+	return ch, nil
+}
+
+// loneCR counts a just read CR as a line break if no LF follows.
+// It is only called with TermAny.
+func (b *Reader) loneCR() bool {
+	if next, err := b.peekByte(); err == nil && next == ASCII_LF {
+		return false
+	}
+	b.line++
+	b.col = 0
+	return true
+}
+
+// isRecordEnd reports whether ch, read outside quotes, terminates the
+// record under the Terminator policy. The LF of a CRLF pair is consumed.
+func (b *Reader) isRecordEnd(ch byte) bool {
+	switch b.Terminator {
+	case TermDefault, TermCRLF:
+		if ch == ASCII_LF {
+			return b.Terminator == TermDefault
+		}
+		if ch == ASCII_CR {
+			if next, err := b.peekByte(); err == nil && next == ASCII_LF {
+				b.readByte()
+				return true
+			}
+		}
+	case TermLF:
+		return ch == ASCII_LF
+	case TermCR:
+		return ch == ASCII_CR
+	case TermAny:
+		if ch == ASCII_CR && !b.loneCR() {
+			b.readByte()
+		}
+		return ch == ASCII_LF || ch == ASCII_CR
+	case TermCustom:
+		return ch == b.RecordSeparator
+	}
+	return false
+}
+
+// startsRecordEnd reports whether buf begins with a record terminator.
+// buf holds at least two bytes unless the input ends sooner.
+func (b *Reader) startsRecordEnd(buf []byte) bool {
+	crlf := len(buf) > 1 && buf[0] == ASCII_CR && buf[1] == ASCII_LF
+	switch b.Terminator {
+	case TermDefault:
+		return buf[0] == ASCII_LF || crlf
+	case TermLF:
+		return buf[0] == ASCII_LF
+	case TermCRLF:
+		return crlf
+	case TermCR:
+		return buf[0] == ASCII_CR
+	case TermAny:
+		return buf[0] == ASCII_LF || buf[0] == ASCII_CR
+	case TermCustom:
+		return buf[0] == b.RecordSeparator
+	}
+	return false
+}
+
+// peekByte returns the next byte without consuming it
+func (b *Reader) peekByte() (byte, error) {
+	buf, err := b.r.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+// errorAt describes err at the position of the last byte read
+func (b *Reader) errorAt(startLine int, err error) ParseError {
+	return ParseError{
+		StartLine: startLine,
+		Line:      b.line,
+		Column:    b.col,
+		Offset:    b.offset - 1,
+		Err:       err,
+	}
+}
+
+// parseError reports err at the position of the last byte read
+func (b *Reader) parseError(startLine int, err error) error {
+	pe := b.errorAt(startLine, err)
+	return &pe
+}
+
+// skipBytes consumes n bytes known to contain no line breaks
+func (b *Reader) skipBytes(n int) {
+	b.r.Discard(n)
+	b.offset += int64(n)
+	b.col += n
+}
+
+// delimiter returns the effective field delimiter split into its first
+// byte and the remaining bytes, which are empty for single-byte delimiters.
+// It validates the reader configuration and returns the record terminator
+// byte that the parser dispatches on.
+func (b *Reader) delimiter() (comma byte, rest string, term byte, err error) {
+	comma = b.Comma
+	if b.Delimiter != "" {
+		comma, rest = b.Delimiter[0], b.Delimiter[1:]
+	}
+	if b.Strict && b.LazyQuotes {
+		return 0, "", 0, ErrInvalidQuoteMode
+	}
+	if b.Encoding < EncodingUTF8 || b.Encoding > EncodingWindows1251 {
+		return 0, "", 0, ErrInvalidEncoding
+	}
+	term = ASCII_LF
+	switch b.Terminator {
+	case TermDefault, TermLF, TermCRLF, TermAny:
+	case TermCR:
+		term = ASCII_CR
+	case TermCustom:
+		term = b.RecordSeparator
+		if term == 0 || term == comma || strings.IndexByte(rest, term) >= 0 ||
+			term == b.Quote || term == b.Comment {
+			return 0, "", 0, ErrInvalidTerminator
+		}
+	default:
+		return 0, "", 0, ErrInvalidTerminator
+	}
+	if comma == ASCII_LF || comma == ASCII_CR || strings.ContainsAny(rest, "\r\n") {
+		return 0, "", 0, ErrInvalidDelimiter
+	}
+	if q := b.Quote; q != 0 && (q == comma || strings.IndexByte(rest, q) >= 0 || q == ASCII_LF || q == ASCII_CR) {
+		return 0, "", 0, ErrInvalidQuote
+	}
+	if c := b.Comment; c != 0 && (c == comma || c == b.Quote || c == ASCII_LF || c == ASCII_CR) {
+		return 0, "", 0, ErrInvalidComment
+	}
+	if e := b.Escape; e != 0 && (e == comma || strings.IndexByte(rest, e) >= 0 || e == b.Quote ||
+		e == b.Comment || e == term || e == ASCII_LF || e == ASCII_CR) {
+		return 0, "", 0, ErrInvalidEscape
+	}
+	return comma, rest, term, nil
+}
+
+// skipLine consumes the input through the next record terminator
+func (b *Reader) skipLine() error {
+	for {
+		ch, err := b.readByte()
+		if err != nil {
+			return err
+		}
+		if b.isRecordEnd(ch) {
+			return nil
+		}
+	}
+}
+
+// checkBOM detects a byte order mark at the start of the input and sets
+// up transcoding. A UTF-8 BOM is stripped unless KeepBOM is set; it still
+// counts for InputOffset but not for columns. UTF-16 input is rejected
+// unless Encoding is a UTF-16 encoding, leaving the BOM unread.
+func (b *Reader) checkBOM() error {
+	buf, _ := b.r.Peek(len(bomUTF8))
+	if b.Encoding != EncodingUTF8 {
+		enc := b.Encoding
+		if enc == EncodingUTF16LE || enc == EncodingUTF16BE {
+			if b.bom = utf16BOM(buf); b.bom != BOMNone {
+				enc = EncodingUTF16LE
+				if b.bom == BOMUTF16BE {
+					enc = EncodingUTF16BE
+				}
+				if !b.KeepBOM {
+					b.r.Discard(2)
+				}
+			}
+		}
+		b.transcode(enc)
+		b.bomChecked = true
+		return nil
+	}
+
+	switch {
+	case string(buf) == bomUTF8:
+		b.bom = BOMUTF8
+		if !b.KeepBOM {
+			b.r.Discard(len(bomUTF8))
+			b.offset += int64(len(bomUTF8))
+		}
+	case utf16BOM(buf) != BOMNone:
+		b.bom = utf16BOM(buf)
+		return ErrUTF16
+	}
+	b.bomChecked = true
+	return nil
+}
+
+// utf16BOM returns the UTF-16 byte order mark buf starts with, if any
+func utf16BOM(buf []byte) BOM {
+	switch {
+	case len(buf) >= 2 && buf[0] == 0xFF && buf[1] == 0xFE:
+		return BOMUTF16LE
+	case len(buf) >= 2 && buf[0] == 0xFE && buf[1] == 0xFF:
+		return BOMUTF16BE
+	}
+	return BOMNone
+}
+
+// transcode makes the reader parse its input decoded from enc
+func (b *Reader) transcode(enc Encoding) {
+	if b.dec == nil {
+		b.dec = &decoder{}
+		b.decoded = bufio.NewReaderSize(b.dec, b.r.Size())
+	}
+	b.dec.reset(b.r, enc)
+	b.decoded.Reset(b.dec)
+	b.raw, b.r = b.r, b.decoded
+}
+
+// atBlankLine reports whether the next line is blank
+func (b *Reader) atBlankLine() bool {
+	buf, _ := b.r.Peek(2)
+	return len(buf) > 0 && b.startsRecordEnd(buf)
+}
+
+// atComment reports whether the next line is a comment line
+func (b *Reader) atComment() bool {
+	if b.Comment == 0 {
+		return false
+	}
+	ch, err := b.peekByte()
+	return err == nil && ch == b.Comment
+}
+
+// isSpace reports whether ch is trimmable whitespace
+func (b *Reader) isSpace(ch, comma byte) bool {
+	if ch == comma {
+		return false
+	}
+	if b.Whitespace == "" {
+		return ch == ' ' || ch == ASCII_TAB
+	}
+	return strings.IndexByte(b.Whitespace, ch) >= 0
+}
+
+// trimTrailingSpace removes trailing whitespace from the current field,
+// keeping the recordBuf bytes before keep which came from quotes, escapes
+// or previous fields
+func (b *Reader) trimTrailingSpace(keep int, comma byte) {
+	n := len(b.recordBuf)
+	for n > keep && b.isSpace(b.recordBuf[n-1], comma) {
+		n--
+	}
+	b.recordBuf = b.recordBuf[:n]
+}
+
+// hasPrefix reports whether the unread input starts with s
+func (b *Reader) hasPrefix(s string) bool {
+	buf, _ := b.r.Peek(len(s))
+	return len(buf) == len(s) && string(buf) == s
+}
+
+// closesQuotedField reports whether the bytes following a closing quote
+// may legally end a quoted field: a delimiter, a line break or EOF.
+func (b *Reader) closesQuotedField(comma byte, rest string) bool {
+	n := 0
+	if b.TrimTrailingSpace {
+		// Skip whitespace between the closing quote and the delimiter
+		for {
+			buf, _ := b.r.Peek(n + 1)
+			if len(buf) <= n || !b.isSpace(buf[n], comma) {
+				break
+			}
+			n++
+		}
 	}
+
+	buf, _ := b.r.Peek(n + max(2, len(rest)+1))
+	buf = buf[min(n, len(buf)):]
+	if len(buf) == 0 {
+		return true
+	}
+	if buf[0] == comma && (rest == "" || strings.HasPrefix(string(buf[1:]), rest)) {
+		return true
+	}
+	return b.startsRecordEnd(buf)
 }
 
+// setSpecial builds the table of bytes the state machine has to look at
+// one by one; everything else is copied in bulk
+func (b *Reader) setSpecial(comma, quote, escape, term byte) {
+	key := [4]byte{comma, quote, escape, term}
+	if b.specialOK && key == b.specialFor {
+		return
+	}
+	b.special = [256]bool{}
+	// The delimiter may be NUL; a zero quote or escape is disabled
+	b.special[comma] = true
+	for _, ch := range key[1:] {
+		if ch != 0 {
+			b.special[ch] = true
+		}
+	}
+	b.special[ASCII_LF] = true
+	b.special[ASCII_CR] = true
+	b.scan.set(&b.special)
+	b.specialFor, b.specialOK = key, true
+}
+
+// buffered returns the unread bytes already in the bufio buffer
+func (b *Reader) buffered() []byte {
+	buf, _ := b.r.Peek(b.r.Buffered())
+	return buf
+}
+
+// readUnquotedRun copies the bytes before the next special byte straight
+// from the bufio buffer into recordBuf. The run holds no line breaks.
+func (b *Reader) readUnquotedRun() {
+	buf := b.buffered()
+	if i := b.plainPrefix(buf); i > 0 {
+		b.recordBuf = append(b.recordBuf, buf[:i]...)
+		b.skipBytes(i)
+	}
+}
+
+// plainPrefix returns the number of leading bytes of buf that are not
+// special. Most fields end within a few bytes, where the table is quicker
+// than a block test; longer runs switch to the SWAR scanner.
+func (b *Reader) plainPrefix(buf []byte) int {
+	if len(buf) > swarMinRun {
+		for i, ch := range buf[:swarMinRun] {
+			if b.special[ch] {
+				return i
+			}
+		}
+		return swarMinRun + b.scan.index(buf[swarMinRun:], &b.special)
+	}
+	for i, ch := range buf {
+		if b.special[ch] {
+			return i
+		}
+	}
+	return len(buf)
+}
+
+// readQuotedRun copies the bytes before the next quote or escape straight
+// from the bufio buffer into recordBuf, counting the line breaks it spans
+func (b *Reader) readQuotedRun(quote byte) {
+	buf := b.buffered()
+	if i := bytes.IndexByte(buf, quote); i >= 0 {
+		buf = buf[:i]
+	}
+	if b.Escape != 0 {
+		if i := bytes.IndexByte(buf, b.Escape); i >= 0 {
+			buf = buf[:i]
+		}
+	}
+	if b.Terminator == TermAny {
+		// Lone CRs are counted one by one
+		if i := bytes.IndexByte(buf, ASCII_CR); i >= 0 {
+			buf = buf[:i]
+		}
+	}
+	if len(buf) == 0 {
+		return
+	}
+
+	b.recordBuf = append(b.recordBuf, buf...)
+	b.offset += int64(len(buf))
+	if last := bytes.LastIndexByte(buf, b.newline); last >= 0 {
+		b.line += bytes.Count(buf, []byte{b.newline})
+		b.col = len(buf) - last - 1
+	} else {
+		b.col += len(buf)
+	}
+	b.r.Discard(len(buf))
+}
+
+// endField completes the field starting at recordBuf index start,
+// trimming it first. quoted is set when the field contained a quoted part
+// and escNull when it began with the escaped null marker.
+func (b *Reader) endField(start, protected int, comma byte, quoted, escNull bool) {
+	if b.TrimTrailingSpace {
+		b.trimTrailingSpace(protected, comma)
+	}
+	f := &b.parsing[len(b.fieldEnds)]
+	f.quoted = quoted
+	if escNull && len(b.recordBuf) == start+1 {
+		b.recordBuf = b.recordBuf[:start]
+		f.null = true
+	} else if b.EmptyIsNull && !quoted && len(b.recordBuf) == start {
+		f.null = true
+	}
+	b.fieldEnds = append(b.fieldEnds, len(b.recordBuf))
+}
+
+// unescape decodes the byte following an Escape character
+func unescape(ch byte) byte {
+	switch ch {
+	case '0':
+		return 0
+	case 'b':
+		return '\b'
+	case 'n':
+		return ASCII_LF
+	case 'r':
+		return ASCII_CR
+	case 't':
+		return ASCII_TAB
+	case 'Z':
+		return 0x1A
+	}
+	return ch
+}
+
+// Read reads one record from the input.
+// The final record is returned with a nil error even when the input lacks
+// a trailing line break; once no records remain, Read returns nil, io.EOF.
+//
+// If the record has an unexpected number of fields, Read returns the
+// record along with a *ParseError wrapping ErrFieldCount.
+//
+// The fields of a record are substrings of a single string, so retaining
+// any one field keeps the memory of the whole record alive. Use
+// strings.Clone on small fields kept from large records.
 func (b *Reader) Read() (dst []string, err error) {
-	var fields []string
+	if err = b.readRecord(); err != nil {
+		return nil, err
+	}
+	err = b.checkFieldCount()
+
+	if b.ReuseRecord {
+		dst = b.lastRecord[:0]
+	}
+	if dst == nil {
+		// Pre-allocate slice with reasonable capacity to reduce reallocations
+		dst = make([]string, 0, max(8, len(b.fieldEnds)))
+	}
+	// Convert the whole record at once and slice the fields out of it,
+	// so that a record costs one string allocation instead of one per field
+	str := string(b.recordBuf)
+	start := 0
+	for _, end := range b.fieldEnds {
+		dst = append(dst, str[start:end])
+		start = end
+	}
+	if b.ReuseRecord {
+		b.lastRecord = dst
+	}
+	return dst, err
+}
+
+// ReadBytes reads one record like Read, but without copying the fields:
+// the returned slices point into a buffer owned by the Reader. The record
+// slice and the bytes of every field are only valid until the next call
+// to Read or ReadBytes, which overwrites them; copy anything that must be
+// kept longer. Fields must not be appended to or modified.
+//
+// ReadBytes honors the same options and returns the same errors as Read.
+func (b *Reader) ReadBytes() ([][]byte, error) {
+	if err := b.readRecord(); err != nil {
+		return nil, err
+	}
+	err := b.checkFieldCount()
+
+	record := b.byteRecord[:0]
+	start := 0
+	for _, end := range b.fieldEnds {
+		record = append(record, b.recordBuf[start:end:end])
+		start = end
+	}
+	b.byteRecord = record
+	return record, err
+}
+
+// ReadRecord reads one record like Read and returns each field together
+// with whether it was quoted or null, so that a,,"" can be told apart:
+// the second field is unquoted and, with EmptyIsNull, null, while the
+// third is a quoted empty string. Values share one string per record as
+// with Read, and ReuseRecord reuses the returned slice.
+//
+// ReadRecord honors the same options and returns the same errors as Read.
+func (b *Reader) ReadRecord() (dst []Field, err error) {
+	if err = b.readRecord(); err != nil {
+		return nil, err
+	}
+	err = b.checkFieldCount()
+
+	if b.ReuseRecord {
+		dst = b.lastFields[:0]
+	}
+	if dst == nil {
+		dst = make([]Field, 0, len(b.fieldEnds))
+	}
+	str := string(b.recordBuf)
+	start := 0
+	for i, end := range b.fieldEnds {
+		f := &b.fields[i]
+		dst = append(dst, Field{Value: str[start:end], Quoted: f.quoted, Null: f.null})
+		start = end
+	}
+	if b.ReuseRecord {
+		b.lastFields = dst
+	}
+	return dst, err
+}
+
+// checkFieldCount enforces FieldsPerRecord on a complete record
+func (b *Reader) checkFieldCount() error {
+	want := b.FieldsPerRecord
+	if want < 0 {
+		return nil
+	}
+	n := len(b.fieldEnds)
+	if want == 0 {
+		b.FieldsPerRecord, b.autoFields = n, true
+		return nil
+	}
+
+	switch {
+	case n < want && (b.RaggedRows == RaggedPad || b.RaggedRows == RaggedFit):
+		// Padding fields are missing, so they are null with EmptyIsNull
+		// and positioned where the record ends
+		pad := b.recordEnd
+		pad.null = b.EmptyIsNull
+		for len(b.fieldEnds) < want {
+			b.fieldEnds = append(b.fieldEnds, len(b.recordBuf))
+			b.fields = append(b.fields, pad)
+		}
+	case n > want && (b.RaggedRows == RaggedTruncate || b.RaggedRows == RaggedFit):
+		b.fieldEnds = b.fieldEnds[:want]
+		b.fields = b.fields[:want]
+	case n != want:
+		// Point at the first extra field, or at the record start
+		if n > want {
+			return b.fieldError(b.recordLine, &b.fields[want], ErrFieldCount)
+		}
+		return b.fieldError(b.recordLine, &b.fields[0], ErrFieldCount)
+	}
+	return nil
+}
+
+// endRecord makes the record just read, which started on startLine and
+// ended at end, the last returned one. Until then, a Read failing or
+// reaching EOF leaves the metadata of the previous record in place.
+func (b *Reader) endRecord(startLine int, end fieldInfo) {
+	b.fields, b.parsing = b.parsing, b.fields
+	b.recordLine = startLine
+	b.recordEnd = end
+}
+
+// fieldError reports err at the start of field p
+func (b *Reader) fieldError(startLine int, p *fieldInfo, err error) error {
+	return &ParseError{
+		StartLine: startLine,
+		Line:      p.line,
+		Column:    p.col,
+		Offset:    p.offset,
+		Err:       err,
+	}
+}
+
+// checkSize enforces MaxFieldBytes and MaxRecordBytes on the record read
+// so far, whose current field starts at recordBuf index fieldStart
+func (b *Reader) checkSize(startLine, fieldStart int) error {
+	if b.MaxFieldBytes > 0 && len(b.recordBuf)-fieldStart > b.MaxFieldBytes ||
+		b.MaxRecordBytes > 0 && len(b.recordBuf) > b.MaxRecordBytes {
+		return b.fieldError(startLine, &b.parsing[len(b.parsing)-1], ErrTooLarge)
+	}
+	return nil
+}
+
+// readRecord parses the next record into recordBuf and fieldEnds
+func (b *Reader) readRecord() error {
 	var inQuotes bool
+	var quoted bool         // the current field contains a quoted part
+	var fieldStart int      // recordBuf index where the current field starts
+	var protected int       // recordBuf bytes before it are never trimmed
+	var escNull bool        // the field starts with the escaped null marker
+	var quoteErr ParseError // position of the opening quote
 
-	// Pre-allocate slice with reasonable capacity to reduce reallocations
-	if cap(fields) < 8 {
-		fields = make([]string, 0, 8)
+	comma, delimRest, term, err := b.delimiter()
+	if err != nil {
+		return err
 	}
+	quote, escape := b.Quote, b.Escape
+	b.newline = ASCII_LF
+	if b.Terminator == TermCR || b.Terminator == TermCustom {
+		b.newline = term
+	}
+	b.setSpecial(comma, quote, escape, term)
 
-	// Reset field buffer but keep capacity
-	b.fieldBuf = b.fieldBuf[:0]
+	if !b.bomChecked {
+		if err := b.checkBOM(); err != nil {
+			return err
+		}
+	}
+
+	// Skip the preamble, comment lines and blank lines
+	for b.line <= b.SkipLines || b.atComment() || b.BlankLines == BlankSkip && b.atBlankLine() {
+		if err := b.skipLine(); err != nil {
+			return err
+		}
+	}
+	if b.BlankLines == BlankError && b.atBlankLine() {
+		pe := &ParseError{StartLine: b.line, Line: b.line, Column: 1, Offset: b.offset, Err: ErrBlankLine}
+		b.skipLine()
+		return pe
+	}
+
+	startLine := b.line
+	startOffset := b.offset
+	limited := b.MaxFieldBytes > 0 || b.MaxRecordBytes > 0
+
+	b.parsing = b.parsing[:0]
+	b.startField()
+
+	// Reset record buffers but keep capacity
+	b.recordBuf = b.recordBuf[:0]
+	b.fieldEnds = b.fieldEnds[:0]
 
 	for {
-		ch, err := b.r.ReadByte()
+		// Copy runs of ordinary bytes in bulk; the state machine below
+		// only sees delimiters, quotes, escapes and line breaks, plus
+		// leading whitespace when it has to be trimmed
+		if inQuotes {
+			b.readQuotedRun(quote)
+		} else if !b.TrimLeadingSpace || quoted || len(b.recordBuf) > fieldStart {
+			b.readUnquotedRun()
+		}
+		if limited {
+			if err := b.checkSize(startLine, fieldStart); err != nil {
+				return err
+			}
+		}
+
+		// Position of ch, in case it ends the record
+		endLine, endCol, endOffset := b.line, b.col+1, b.offset
+		ch, err := b.readByte()
 		if err != nil {
-			if err == io.EOF && len(b.fieldBuf) > 0 {
-				// Handle last field if we have content
-				fields = append(fields, string(b.fieldBuf))
+			if err != io.EOF || b.offset == startOffset {
+				// Read error, or no more records
+				return err
+			}
+			if inQuotes {
+				// Copy so that quoteErr does not escape to the heap
+				pe := quoteErr
+				pe.Err = ErrUnterminatedQuote
+				return &pe
 			}
-			return fields, err
+			// Final record without a line break - io.EOF is returned
+			// by the next call
+			b.endField(fieldStart, protected, comma, quoted, escNull)
+			b.endRecord(startLine, fieldInfo{line: endLine, col: endCol, offset: endOffset})
+			return nil
 		}
 
+		// The delimiter is matched first: it may be NUL, while a zero
+		// quote or escape is disabled
 		switch ch {
-		case ASCII_DQ: // Double quote
+		case comma: // Field separator
 			if inQuotes {
-				// Check if this is an escaped quote (double quote)
-				nextCh, err := b.r.ReadByte()
-				if err == nil && nextCh == ASCII_DQ {
+				// Comma inside quotes is part of the field
+				b.recordBuf = append(b.recordBuf, ch)
+			} else if delimRest != "" && !b.hasPrefix(delimRest) {
+				// First byte of a multi-byte delimiter on its own is data
+				b.recordBuf = append(b.recordBuf, ch)
+			} else {
+				b.skipBytes(len(delimRest))
+				// End of field - the next one continues in recordBuf
+				b.endField(fieldStart, protected, comma, quoted, escNull)
+				fieldStart, protected = len(b.recordBuf), len(b.recordBuf)
+				quoted, escNull = false, false
+				b.startField()
+				if b.MaxFields > 0 && len(b.parsing) > b.MaxFields {
+					return b.fieldError(startLine, &b.parsing[len(b.parsing)-1], ErrTooLarge)
+				}
+			}
+
+		case quote: // Quote character
+			if quote == 0 {
+				// Quoting disabled - NUL, unless it is the delimiter
+				// matched above, is a regular character
+				b.recordBuf = append(b.recordBuf, ch)
+			} else if inQuotes {
+				// Check if this is an escaped quote (doubled quote)
+				nextCh, err := b.peekByte()
+				if err == nil && nextCh == quote {
 					// Escaped quote - add single quote to field
-					b.fieldBuf = append(b.fieldBuf, ASCII_DQ)
+					b.readByte()
+					b.recordBuf = append(b.recordBuf, quote)
+				} else if b.LazyQuotes && !b.closesQuotedField(comma, delimRest) {
+					// Stray quote inside a quoted field is literal
+					b.recordBuf = append(b.recordBuf, quote)
 				} else {
-					// End of quoted field - put back the character if not EOF
-					if err == nil {
-						b.r.UnreadByte()
-					}
+					// End of quoted field
 					inQuotes = false
+					protected = len(b.recordBuf)
+					if b.Strict && !b.closesQuotedField(comma, delimRest) {
+						// Skip the whitespace closesQuotedField looked past,
+						// then point at the offending byte before reading it,
+						// as a line break moves the position to the next line
+						for b.TrimTrailingSpace {
+							if next, err := b.peekByte(); err != nil || !b.isSpace(next, comma) {
+								break
+							}
+							b.readByte()
+						}
+						pe := b.errorAt(startLine, ErrQuote)
+						pe.Column, pe.Offset = b.col+1, b.offset
+						b.readByte()
+						return &pe
+					}
 				}
 			} else {
+				if b.Strict && len(b.recordBuf) > fieldStart {
+					// Quotes are only allowed around the whole field
+					return b.parseError(startLine, ErrBareQuote)
+				}
+				if b.LazyQuotes && len(b.recordBuf) > fieldStart {
+					// Bare quote in a non-quoted field is literal
+					b.recordBuf = append(b.recordBuf, quote)
+					break
+				}
 				// Start of quoted field
 				inQuotes = true
+				quoted = true
+				escNull = false
+				quoteErr = b.errorAt(startLine, nil)
 			}
 
-		case b.Comma: // Field separator
-			if inQuotes {
-				// Comma inside quotes is part of the field
-				b.fieldBuf = append(b.fieldBuf, ch)
-			} else {
-				// End of field - use fieldBuf directly to avoid string copying
-				fields = append(fields, string(b.fieldBuf))
-				b.fieldBuf = b.fieldBuf[:0] // reset but keep capacity
-			}
-
-		case ASCII_LF: // Line feed
-			if inQuotes {
-				// LF inside quotes is part of the field
-				b.fieldBuf = append(b.fieldBuf, ch)
-			} else {
+		case ASCII_LF, ASCII_CR, term: // Line break or record terminator
+			if !inQuotes && b.isRecordEnd(ch) {
 				// End of record - add the last field and return
-				fields = append(fields, string(b.fieldBuf))
-				return fields, nil
+				b.endField(fieldStart, protected, comma, quoted, escNull)
+				b.endRecord(startLine, fieldInfo{line: endLine, col: endCol, offset: endOffset})
+				return nil
+			}
+			if inQuotes && ch == ASCII_CR && b.Terminator == TermAny {
+				b.loneCR()
 			}
+			// Line break inside quotes, or not a terminator under the
+			// current policy - part of the field
+			b.recordBuf = append(b.recordBuf, ch)
 
-		case ASCII_CR: // Carriage return
-			if inQuotes {
-				// CR inside quotes is part of the field
-				b.fieldBuf = append(b.fieldBuf, ch)
-			} else {
-				// Check if followed by LF for CRLF sequence
-				nextCh, err := b.r.ReadByte()
-				if err == nil && nextCh == ASCII_LF {
-					// CRLF - end of record
-					fields = append(fields, string(b.fieldBuf))
-					return fields, nil
-				} else {
-					// Just CR - treat as regular character
-					b.fieldBuf = append(b.fieldBuf, ch)
-					if err == nil {
-						b.r.UnreadByte()
-					}
-				}
+		case escape: // Escape character
+			if escape == 0 {
+				// Escaping disabled - NUL is a regular character
+				b.recordBuf = append(b.recordBuf, ch)
+				break
+			}
+			nextCh, err := b.readByte()
+			if err != nil {
+				// Escape at end of input is kept as data
+				b.recordBuf = append(b.recordBuf, ch)
+				break
 			}
+			if nextCh == 'N' && !quoted && len(b.recordBuf) == fieldStart {
+				escNull = true
+			}
+			b.recordBuf = append(b.recordBuf, unescape(nextCh))
+			protected = len(b.recordBuf)
 
 		default:
+			if b.TrimLeadingSpace && !quoted && len(b.recordBuf) == fieldStart && b.isSpace(ch, comma) {
+				// Leading whitespace before the field
+				break
+			}
 			// Regular character - add to current field
-			b.fieldBuf = append(b.fieldBuf, ch)
+			b.recordBuf = append(b.recordBuf, ch)
 		}
 	}
 }
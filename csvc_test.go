@@ -2,10 +2,16 @@ package csvc
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode/utf16"
 )
 
 func TestNewReader(t *testing.T) {
@@ -17,6 +23,14 @@ func TestNewReader(t *testing.T) {
 		t.Errorf("Expected default comma to be ',', got %c", reader.Comma)
 	}
 
+	if reader.FieldsPerRecord != -1 {
+		t.Errorf("Expected default FieldsPerRecord to be -1, got %d", reader.FieldsPerRecord)
+	}
+
+	if reader.Quote != ASCII_DQ {
+		t.Errorf("Expected default quote to be %q, got %q", ASCII_DQ, reader.Quote)
+	}
+
 	if reader.r == nil {
 		t.Error("Expected reader to be initialized")
 	}
@@ -207,6 +221,7 @@ func TestReader_Read_CustomDelimiter(t *testing.T) {
 		name      string
 		input     string
 		delimiter byte
+		noQuote   bool // set Quote to 0
 		expected  []string
 	}{
 		{
@@ -227,6 +242,25 @@ func TestReader_Read_CustomDelimiter(t *testing.T) {
 			delimiter: '|',
 			expected:  []string{"field1", "field2", "field3"},
 		},
+		{
+			name:      "NUL delimiter",
+			input:     "a\x00b\n",
+			delimiter: 0,
+			expected:  []string{"a", "b"},
+		},
+		{
+			name:      "NUL delimiter after a long run",
+			input:     strings.Repeat("x", 100) + "\x00b\n",
+			delimiter: 0,
+			expected:  []string{strings.Repeat("x", 100), "b"},
+		},
+		{
+			name:      "NUL delimiter without quoting",
+			input:     "a\x00\"b\"\n",
+			delimiter: 0,
+			noQuote:   true,
+			expected:  []string{"a", "\"b\""},
+		},
 	}
 
 	for _, tt := range tests {
@@ -234,6 +268,9 @@ func TestReader_Read_CustomDelimiter(t *testing.T) {
 			r := bufio.NewReader(strings.NewReader(tt.input))
 			reader := NewReader(r)
 			reader.Comma = tt.delimiter
+			if tt.noQuote {
+				reader.Quote = 0
+			}
 
 			result, err := reader.Read()
 			if err != nil {
@@ -386,3 +423,2869 @@ func TestReader_Read_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestReader_Read_Strict(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "quoted and unquoted fields",
+			input:    "a,\"b,c\",\"d\"\"e\"\n",
+			expected: []string{"a", "b,c", "d\"e"},
+		},
+		{
+			name:     "quoted field before CRLF",
+			input:    "a,\"b\"\r\n",
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "empty quoted field",
+			input:    "\"\",a\n",
+			expected: []string{"", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.Strict = true
+
+			result, err := reader.Read()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReader_Read_StrictErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		reads    int // number of successful reads before the error
+		expected ParseError
+	}{
+		{
+			name:     "bare quote in unquoted field",
+			input:    "a\"b\"c\n",
+			expected: ParseError{StartLine: 1, Line: 1, Column: 2, Offset: 1, Err: ErrBareQuote},
+		},
+		{
+			name:     "bare quote in second field",
+			input:    "abc,de\"f\n",
+			expected: ParseError{StartLine: 1, Line: 1, Column: 7, Offset: 6, Err: ErrBareQuote},
+		},
+		{
+			name:     "text after closing quote",
+			input:    "\"ab\"c,d\n",
+			expected: ParseError{StartLine: 1, Line: 1, Column: 5, Offset: 4, Err: ErrQuote},
+		},
+		{
+			name:     "lone CR after closing quote",
+			input:    "\"ab\"\rc\n",
+			expected: ParseError{StartLine: 1, Line: 1, Column: 5, Offset: 4, Err: ErrQuote},
+		},
+		{
+			name:     "error on second line",
+			input:    "a,b\nc,d\"\n",
+			reads:    1,
+			expected: ParseError{StartLine: 2, Line: 2, Column: 4, Offset: 7, Err: ErrBareQuote},
+		},
+		{
+			name:     "error after multiline quoted field",
+			input:    "\"a\nb\",\"c\"d\n",
+			expected: ParseError{StartLine: 1, Line: 2, Column: 7, Offset: 9, Err: ErrQuote},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.Strict = true
+
+			for i := 0; i < tt.reads; i++ {
+				if _, err := reader.Read(); err != nil {
+					t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+				}
+			}
+
+			result, err := reader.Read()
+			if result != nil {
+				t.Errorf("Expected nil record, got %v", result)
+			}
+
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Expected *ParseError, got %v", err)
+			}
+			if !errors.Is(err, tt.expected.Err) {
+				t.Errorf("Expected errors.Is(err, %v), got %v", tt.expected.Err, err)
+			}
+			if *perr != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *perr)
+			}
+		})
+	}
+}
+
+func TestReader_Read_NonStrictAcceptsMalformed(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "bare quotes",
+			input:    "a\"b\"c\n",
+			expected: []string{"abc"},
+		},
+		{
+			name:     "text after closing quote",
+			input:    "\"ab\"c,d\n",
+			expected: []string{"abc", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+
+			result, err := reader.Read()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseError_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *ParseError
+		expected string
+	}{
+		{
+			name:     "single line",
+			err:      &ParseError{StartLine: 3, Line: 3, Column: 5, Offset: 42, Err: ErrBareQuote},
+			expected: "parse error on line 3, column 5 (offset 42): bare \" in non-quoted field",
+		},
+		{
+			name:     "multiline record",
+			err:      &ParseError{StartLine: 3, Line: 4, Column: 2, Offset: 50, Err: ErrQuote},
+			expected: "record on line 3; parse error on line 4, column 2 (offset 50): extraneous or missing \" in quoted field",
+		},
+		{
+			name:     "field count",
+			err:      &ParseError{StartLine: 3, Line: 3, Column: 5, Offset: 42, Err: ErrFieldCount},
+			expected: "record on line 3, column 5 (offset 42): wrong number of fields",
+		},
+		{
+			name:     "field count in multiline record",
+			err:      &ParseError{StartLine: 2, Line: 3, Column: 6, Offset: 20, Err: ErrFieldCount},
+			expected: "record on line 2; parse error on line 3, column 6 (offset 20): wrong number of fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestReader_Read_UnterminatedQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		reads    int // number of successful reads before the error
+		expected ParseError
+	}{
+		{
+			name:     "single field",
+			input:    "\"abc",
+			expected: ParseError{StartLine: 1, Line: 1, Column: 1, Offset: 0, Err: ErrUnterminatedQuote},
+		},
+		{
+			name:     "last field of record",
+			input:    "a,b,\"c,d\n",
+			expected: ParseError{StartLine: 1, Line: 1, Column: 5, Offset: 4, Err: ErrUnterminatedQuote},
+		},
+		{
+			name:     "truncated after several records",
+			input:    "a,b\nc,d\ne,\"f\ng,h\ni,j\n",
+			reads:    2,
+			expected: ParseError{StartLine: 3, Line: 3, Column: 3, Offset: 10, Err: ErrUnterminatedQuote},
+		},
+		{
+			name:     "opening quote on a later line of the record",
+			input:    "\"a\nb\",\"c\nd",
+			expected: ParseError{StartLine: 1, Line: 2, Column: 4, Offset: 6, Err: ErrUnterminatedQuote},
+		},
+		{
+			name:     "escaped quote before EOF",
+			input:    "\"abc\"\"",
+			expected: ParseError{StartLine: 1, Line: 1, Column: 1, Offset: 0, Err: ErrUnterminatedQuote},
+		},
+		{
+			name:     "truncated multiline field",
+			input:    "a,\"b\nc\nd",
+			expected: ParseError{StartLine: 1, Line: 1, Column: 3, Offset: 2, Err: ErrUnterminatedQuote},
+		},
+	}
+
+	// Truncation is detected whatever the quote mode
+	modes := []struct {
+		name   string
+		strict bool
+		lazy   bool
+	}{
+		{"default", false, false},
+		{"strict", true, false},
+		{"lazy", false, true},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range modes {
+			t.Run(tt.name+"/"+mode.name, func(t *testing.T) {
+				reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+				reader.Strict = mode.strict
+				reader.LazyQuotes = mode.lazy
+
+				for i := 0; i < tt.reads; i++ {
+					if _, err := reader.Read(); err != nil {
+						t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+					}
+				}
+
+				result, err := reader.Read()
+				if result != nil {
+					t.Errorf("Expected nil record, got %v", result)
+				}
+
+				var perr *ParseError
+				if !errors.As(err, &perr) {
+					t.Fatalf("Expected *ParseError, got %v", err)
+				}
+				if !errors.Is(err, ErrUnterminatedQuote) {
+					t.Errorf("Expected errors.Is(err, ErrUnterminatedQuote), got %v", err)
+				}
+				if *perr != tt.expected {
+					t.Errorf("Expected %+v, got %+v", tt.expected, *perr)
+				}
+			})
+		}
+	}
+}
+
+func TestReader_Read_StrictClosedQuoteAtEOF(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("a,\"b\"")))
+	reader.Strict = true
+
+	result, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"a", "b"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("Expected EOF error, got %v", err)
+	}
+}
+
+func TestReader_Read_FinalRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected [][]string
+	}{
+		{
+			name:     "no trailing newline",
+			input:    "a,b\nc,d",
+			expected: [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name:     "trailing newline",
+			input:    "a,b\nc,d\n",
+			expected: [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name:     "trailing CRLF",
+			input:    "a,b\r\nc,d\r\n",
+			expected: [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name:     "trailing empty field without newline",
+			input:    "a,b,",
+			expected: [][]string{{"a", "b", ""}},
+		},
+		{
+			name:     "trailing empty field with newline",
+			input:    "a,b,\n",
+			expected: [][]string{{"a", "b", ""}},
+		},
+		{
+			name:     "single delimiter",
+			input:    ",",
+			expected: [][]string{{"", ""}},
+		},
+		{
+			name:     "empty quoted field",
+			input:    "a,\"\"",
+			expected: [][]string{{"a", ""}},
+		},
+		{
+			name:     "trailing lone CR",
+			input:    "a,b\r",
+			expected: [][]string{{"a", "b\r"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+
+			for i, expected := range tt.expected {
+				result, err := reader.Read()
+				if err != nil {
+					t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+				}
+				if !reflect.DeepEqual(result, expected) {
+					t.Errorf("Read %d: expected %q, got %q", i+1, expected, result)
+				}
+			}
+
+			// EOF is sticky once all records are consumed
+			for i := 0; i < 2; i++ {
+				result, err := reader.Read()
+				if err != io.EOF {
+					t.Errorf("Expected EOF error, got %v", err)
+				}
+				if result != nil {
+					t.Errorf("Expected nil result after EOF, got %v", result)
+				}
+			}
+		})
+	}
+}
+
+func TestReader_Positions(t *testing.T) {
+	type fieldPos struct{ line, col int }
+	tests := []struct {
+		name    string
+		input   string
+		lines   []int        // Line() after each read
+		offsets []int64      // InputOffset() after each read
+		fields  [][]fieldPos // FieldPos() for every field of each record
+	}{
+		{
+			name:    "simple records",
+			input:   "a,bb,c\nd,e\n",
+			lines:   []int{1, 2},
+			offsets: []int64{7, 11},
+			fields: [][]fieldPos{
+				{{1, 1}, {1, 3}, {1, 6}},
+				{{2, 1}, {2, 3}},
+			},
+		},
+		{
+			name:    "CRLF and empty fields",
+			input:   ",x,\r\ny\r\n",
+			lines:   []int{1, 2},
+			offsets: []int64{5, 8},
+			fields: [][]fieldPos{
+				{{1, 1}, {1, 2}, {1, 4}},
+				{{2, 1}},
+			},
+		},
+		{
+			name:    "multiline quoted field",
+			input:   "a,\"b\nc\nd\",e\nf\n",
+			lines:   []int{1, 4},
+			offsets: []int64{12, 14},
+			fields: [][]fieldPos{
+				{{1, 1}, {1, 3}, {3, 4}},
+				{{4, 1}},
+			},
+		},
+		{
+			name:    "final record without newline",
+			input:   "a\nb,",
+			lines:   []int{1, 2},
+			offsets: []int64{2, 4},
+			fields: [][]fieldPos{
+				{{1, 1}},
+				{{2, 1}, {2, 3}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+
+			if line := reader.Line(); line != 0 {
+				t.Errorf("Expected line 0 before first read, got %d", line)
+			}
+
+			for i := range tt.fields {
+				record, err := reader.Read()
+				if err != nil {
+					t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+				}
+				if len(record) != len(tt.fields[i]) {
+					t.Fatalf("Read %d: expected %d fields, got %d", i+1, len(tt.fields[i]), len(record))
+				}
+				if line := reader.Line(); line != tt.lines[i] {
+					t.Errorf("Read %d: expected line %d, got %d", i+1, tt.lines[i], line)
+				}
+				if offset := reader.InputOffset(); offset != tt.offsets[i] {
+					t.Errorf("Read %d: expected offset %d, got %d", i+1, tt.offsets[i], offset)
+				}
+				for j, want := range tt.fields[i] {
+					line, col := reader.FieldPos(j)
+					if line != want.line || col != want.col {
+						t.Errorf("Read %d, field %d: expected %d:%d, got %d:%d",
+							i+1, j, want.line, want.col, line, col)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReader_FieldPos_OutOfRange(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("a,b\n")))
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, index := range []int{-1, 2} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic for index %d", index)
+				}
+			}()
+			reader.FieldPos(index)
+		}()
+	}
+}
+
+func TestReader_FieldPos_AfterEOFAndError(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("a,\"b\"\nc,d\"")))
+	reader.Strict = true
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A failed Read keeps describing the record returned before it
+	check := func(when string) {
+		if line, col := reader.FieldPos(1); line != 1 || col != 3 {
+			t.Errorf("%s: expected field 1 at 1:3, got %d:%d", when, line, col)
+		}
+		if !reader.IsQuoted(1) {
+			t.Errorf("%s: expected field 1 to be quoted", when)
+		}
+		if line := reader.Line(); line != 1 {
+			t.Errorf("%s: expected Line 1, got %d", when, line)
+		}
+	}
+
+	if _, err := reader.Read(); !errors.Is(err, ErrBareQuote) {
+		t.Fatalf("Expected ErrBareQuote, got %v", err)
+	}
+	check("after an error")
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Fatalf("Expected EOF error, got %v", err)
+	}
+	check("after EOF")
+}
+
+func TestReader_IsNull_OutOfRange(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("a,b\n")))
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, index := range []int{-1, 2} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic for index %d", index)
+				}
+			}()
+			reader.IsNull(index)
+		}()
+	}
+}
+
+func TestReader_Read_CustomQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		quote    byte
+		expected []string
+	}{
+		{
+			name:     "single quotes",
+			input:    "'a,b',c\n",
+			quote:    '\'',
+			expected: []string{"a,b", "c"},
+		},
+		{
+			name:     "escaped single quote",
+			input:    "'it''s',\"x\"\n",
+			quote:    '\'',
+			expected: []string{"it's", "\"x\""},
+		},
+		{
+			name:     "single quoted multiline field",
+			input:    "'a\nb',c\n",
+			quote:    '\'',
+			expected: []string{"a\nb", "c"},
+		},
+		{
+			name:     "quoting disabled keeps inch marks",
+			input:    "Monitor 27\",\"glossy\",12\"\n",
+			quote:    0,
+			expected: []string{"Monitor 27\"", "\"glossy\"", "12\""},
+		},
+		{
+			name:     "quoting disabled splits on every delimiter",
+			input:    "\"a,b\"\n",
+			quote:    0,
+			expected: []string{"\"a", "b\""},
+		},
+		{
+			name:     "quoting disabled keeps NUL bytes",
+			input:    "a\x00b,c\n",
+			quote:    0,
+			expected: []string{"a\x00b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.Quote = tt.quote
+
+			result, err := reader.Read()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReader_Read_CustomQuoteStrict(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("'a'b\n")))
+	reader.Quote = '\''
+	reader.Strict = true
+
+	_, err := reader.Read()
+	if !errors.Is(err, ErrQuote) {
+		t.Errorf("Expected ErrQuote, got %v", err)
+	}
+
+	// With quoting disabled no quote error is possible
+	reader = NewReader(bufio.NewReader(strings.NewReader("a\"b\"c\n")))
+	reader.Quote = 0
+	reader.Strict = true
+
+	result, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"a\"b\"c"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestReader_Read_InvalidQuote(t *testing.T) {
+	for _, quote := range []byte{',', ASCII_LF, ASCII_CR} {
+		reader := NewReader(bufio.NewReader(strings.NewReader("a,b\n")))
+		reader.Quote = quote
+
+		if _, err := reader.Read(); err != ErrInvalidQuote {
+			t.Errorf("Quote %q: expected ErrInvalidQuote, got %v", quote, err)
+		}
+	}
+}
+
+func TestReader_Read_MultiByteDelimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		delimiter string
+		noQuote   bool // set Quote to 0
+		expected  [][]string
+	}{
+		{
+			name:      "double pipe",
+			input:     "a||b||c\n",
+			delimiter: "||",
+			expected:  [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:      "single pipe is data",
+			input:     "a|b||c|\n",
+			delimiter: "||",
+			expected:  [][]string{{"a|b", "c|"}},
+		},
+		{
+			name:      "NUL pair",
+			input:     "a\x00\x00b\x00c\n",
+			delimiter: "\x00\x00",
+			expected:  [][]string{{"a", "b\x00c"}},
+		},
+		{
+			name:      "NUL pair without quoting",
+			input:     "a\x00\x00b\x00c\nd\x00\x00e\n",
+			delimiter: "\x00\x00",
+			noQuote:   true,
+			expected:  [][]string{{"a", "b\x00c"}, {"d", "e"}},
+		},
+		{
+			name:      "broken bar rune",
+			input:     "a¦b¦¦c\n",
+			delimiter: "¦",
+			expected:  [][]string{{"a", "b", "", "c"}},
+		},
+		{
+			name:      "section sign rune",
+			input:     "x§y\nz§\n",
+			delimiter: "§",
+			expected:  [][]string{{"x", "y"}, {"z", ""}},
+		},
+		{
+			name:      "delimiter inside quotes",
+			input:     "\"a||b\"||c\n",
+			delimiter: "||",
+			expected:  [][]string{{"a||b", "c"}},
+		},
+		{
+			name:      "partial delimiter at EOF",
+			input:     "a::b:",
+			delimiter: "::",
+			expected:  [][]string{{"a", "b:"}},
+		},
+		{
+			name:      "overlapping delimiter bytes",
+			input:     "a:::b\n",
+			delimiter: "::",
+			expected:  [][]string{{"a", ":b"}},
+		},
+		{
+			name:      "single-byte delimiter overrides Comma",
+			input:     "a,b;c\n",
+			delimiter: ";",
+			expected:  [][]string{{"a,b", "c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.Delimiter = tt.delimiter
+			if tt.noQuote {
+				reader.Quote = 0
+			}
+
+			for i, expected := range tt.expected {
+				result, err := reader.Read()
+				if err != nil {
+					t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+				}
+				if !reflect.DeepEqual(result, expected) {
+					t.Errorf("Read %d: expected %q, got %q", i+1, expected, result)
+				}
+			}
+			if _, err := reader.Read(); err != io.EOF {
+				t.Errorf("Expected EOF error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReader_Read_MultiByteDelimiterStrict(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("\"a\"||\"b\"\n\"c\"|d\n")))
+	reader.Delimiter = "||"
+	reader.Strict = true
+
+	result, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if line, col := reader.FieldPos(1); line != 1 || col != 6 {
+		t.Errorf("Expected field 1 at 1:6, got %d:%d", line, col)
+	}
+
+	_, err = reader.Read()
+	if !errors.Is(err, ErrQuote) {
+		t.Errorf("Expected ErrQuote, got %v", err)
+	}
+}
+
+func TestReader_Read_InvalidDelimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		comma     byte
+		delimiter string
+		expected  error
+	}{
+		{name: "LF comma", comma: ASCII_LF, expected: ErrInvalidDelimiter},
+		{name: "CR comma", comma: ASCII_CR, expected: ErrInvalidDelimiter},
+		{name: "delimiter with line break", comma: ',', delimiter: "|\n", expected: ErrInvalidDelimiter},
+		{name: "delimiter starting with quote", comma: ',', delimiter: "\"|", expected: ErrInvalidQuote},
+		{name: "delimiter containing quote", comma: ',', delimiter: "|\"", expected: ErrInvalidQuote},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader("a,b\n")))
+			reader.Comma = tt.comma
+			reader.Delimiter = tt.delimiter
+
+			if _, err := reader.Read(); err != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestReader_Read_FieldsPerRecord(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		fieldsPerRecord int
+		policy          RaggedPolicy
+		expected        [][]string
+		errors          []*ParseError // expected error per read, nil for none
+	}{
+		{
+			name:            "variable fields by default",
+			input:           "a,b\nc\nd,e,f\n",
+			fieldsPerRecord: -1,
+			expected:        [][]string{{"a", "b"}, {"c"}, {"d", "e", "f"}},
+			errors:          []*ParseError{nil, nil, nil},
+		},
+		{
+			name:            "fixed count",
+			input:           "a,b\nc\nd,e,f\ng,h\n",
+			fieldsPerRecord: 2,
+			expected:        [][]string{{"a", "b"}, {"c"}, {"d", "e", "f"}, {"g", "h"}},
+			errors: []*ParseError{
+				nil,
+				{StartLine: 2, Line: 2, Column: 1, Offset: 4, Err: ErrFieldCount},
+				{StartLine: 3, Line: 3, Column: 5, Offset: 10, Err: ErrFieldCount},
+				nil,
+			},
+		},
+		{
+			name:            "locked to first record",
+			input:           "a,b,c\nd,e\nf,g,h\n",
+			fieldsPerRecord: 0,
+			expected:        [][]string{{"a", "b", "c"}, {"d", "e"}, {"f", "g", "h"}},
+			errors: []*ParseError{
+				nil,
+				{StartLine: 2, Line: 2, Column: 1, Offset: 6, Err: ErrFieldCount},
+				nil,
+			},
+		},
+		{
+			name:            "pad short records",
+			input:           "a,b,c\nd\ne,f,g,h\n",
+			fieldsPerRecord: 0,
+			policy:          RaggedPad,
+			expected:        [][]string{{"a", "b", "c"}, {"d", "", ""}, {"e", "f", "g", "h"}},
+			errors: []*ParseError{
+				nil,
+				nil,
+				{StartLine: 3, Line: 3, Column: 7, Offset: 14, Err: ErrFieldCount},
+			},
+		},
+		{
+			name:            "truncate long records",
+			input:           "a,b\nc,d,e,f\ng\n",
+			fieldsPerRecord: 2,
+			policy:          RaggedTruncate,
+			expected:        [][]string{{"a", "b"}, {"c", "d"}, {"g"}},
+			errors: []*ParseError{
+				nil,
+				nil,
+				{StartLine: 3, Line: 3, Column: 1, Offset: 12, Err: ErrFieldCount},
+			},
+		},
+		{
+			name:            "fit pads and truncates",
+			input:           "a,b\nc,d,e\nf\n",
+			fieldsPerRecord: 2,
+			policy:          RaggedFit,
+			expected:        [][]string{{"a", "b"}, {"c", "d"}, {"f", ""}},
+			errors:          []*ParseError{nil, nil, nil},
+		},
+		{
+			name:            "multiline record reports start line",
+			input:           "a,b\n\"c\nd\",e,f\n",
+			fieldsPerRecord: 2,
+			expected:        [][]string{{"a", "b"}, {"c\nd", "e", "f"}},
+			errors: []*ParseError{
+				nil,
+				{StartLine: 2, Line: 3, Column: 6, Offset: 12, Err: ErrFieldCount},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.FieldsPerRecord = tt.fieldsPerRecord
+			reader.RaggedRows = tt.policy
+
+			for i, expected := range tt.expected {
+				result, err := reader.Read()
+				if !reflect.DeepEqual(result, expected) {
+					t.Errorf("Read %d: expected %q, got %q", i+1, expected, result)
+				}
+
+				if tt.errors[i] == nil {
+					if err != nil {
+						t.Errorf("Read %d: unexpected error: %v", i+1, err)
+					}
+					continue
+				}
+				var perr *ParseError
+				if !errors.As(err, &perr) {
+					t.Fatalf("Read %d: expected *ParseError, got %v", i+1, err)
+				}
+				if *perr != *tt.errors[i] {
+					t.Errorf("Read %d: expected %+v, got %+v", i+1, *tt.errors[i], *perr)
+				}
+			}
+		})
+	}
+}
+
+func TestReader_Read_RaggedFieldPos(t *testing.T) {
+	// Padded fields report the position where the record ends
+	tests := []struct {
+		name      string
+		input     string
+		line, col int
+	}{
+		{"LF", "a,b,c\nd,e\n", 2, 4},
+		{"CRLF", "a,b,c\r\nd,e\r\n", 2, 4},
+		{"end of input", "a,b,c\nd,e", 2, 4},
+		{"multiline quoted field", "a,b,c\nd,\"e\nf\"\n", 3, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(test.input)))
+			reader.FieldsPerRecord = 0
+			reader.RaggedRows = RaggedPad
+
+			for i := 0; i < 2; i++ {
+				if _, err := reader.Read(); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			if line, col := reader.FieldPos(2); line != test.line || col != test.col {
+				t.Errorf("Expected padded field at %d:%d, got %d:%d", test.line, test.col, line, col)
+			}
+			if line, col := reader.FieldPos(1); line != 2 || col != 3 {
+				t.Errorf("Expected last real field at 2:3, got %d:%d", line, col)
+			}
+		})
+	}
+}
+
+func TestReader_Read_Comments(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		comment   byte
+		skipLines int
+		expected  [][]string
+		lines     []int // Line() after each read
+	}{
+		{
+			name:     "comment lines skipped",
+			input:    "# header comment\na,b\n# another\r\nc,d\n#",
+			comment:  '#',
+			expected: [][]string{{"a", "b"}, {"c", "d"}},
+			lines:    []int{2, 4},
+		},
+		{
+			name:     "comment character inside record is data",
+			input:    "a,#b\n\"#c\",d\n",
+			comment:  '#',
+			expected: [][]string{{"a", "#b"}, {"#c", "d"}},
+			lines:    []int{1, 2},
+		},
+		{
+			name:     "comment inside quoted field is data",
+			input:    "\"a\n# not a comment\",b\n",
+			comment:  '#',
+			expected: [][]string{{"a\n# not a comment", "b"}},
+			lines:    []int{1},
+		},
+		{
+			name:     "comments disabled",
+			input:    "# a\nb\n",
+			expected: [][]string{{"# a"}, {"b"}},
+			lines:    []int{1, 2},
+		},
+		{
+			name:      "preamble skipped",
+			input:     "Instrument: X-100\nDate: 2024-01-01\n\ntime,value\n0,1.5\n",
+			skipLines: 3,
+			expected:  [][]string{{"time", "value"}, {"0", "1.5"}},
+			lines:     []int{4, 5},
+		},
+		{
+			name:      "preamble with quotes and delimiters",
+			input:     "\"unbalanced,\nx\na\n",
+			skipLines: 2,
+			expected:  [][]string{{"a"}},
+			lines:     []int{3},
+		},
+		{
+			name:      "preamble and comments",
+			input:     "meta\n;note\na;b\n",
+			comment:   ';',
+			skipLines: 1,
+			expected:  [][]string{{"a;b"}},
+			lines:     []int{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.Comment = tt.comment
+			reader.SkipLines = tt.skipLines
+
+			for i, expected := range tt.expected {
+				result, err := reader.Read()
+				if err != nil {
+					t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+				}
+				if !reflect.DeepEqual(result, expected) {
+					t.Errorf("Read %d: expected %q, got %q", i+1, expected, result)
+				}
+				if line := reader.Line(); line != tt.lines[i] {
+					t.Errorf("Read %d: expected line %d, got %d", i+1, tt.lines[i], line)
+				}
+			}
+			if _, err := reader.Read(); err != io.EOF {
+				t.Errorf("Expected EOF error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReader_Read_SkipLinesPastEOF(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("a\nb")))
+	reader.SkipLines = 5
+
+	result, err := reader.Read()
+	if err != io.EOF {
+		t.Errorf("Expected EOF error, got %v", err)
+	}
+	if result != nil {
+		t.Errorf("Expected nil result, got %v", result)
+	}
+}
+
+func TestReader_Read_InvalidComment(t *testing.T) {
+	for _, comment := range []byte{',', ASCII_DQ, ASCII_LF, ASCII_CR} {
+		reader := NewReader(bufio.NewReader(strings.NewReader("a,b\n")))
+		reader.Comment = comment
+
+		if _, err := reader.Read(); err != ErrInvalidComment {
+			t.Errorf("Comment %q: expected ErrInvalidComment, got %v", comment, err)
+		}
+	}
+}
+
+func TestReader_Read_TrimSpace(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		leading    bool
+		trailing   bool
+		whitespace string
+		comma      byte
+		strict     bool
+		expected   []string
+	}{
+		{
+			name:     "leading only",
+			input:    "  a ,\tb\t, c\n",
+			leading:  true,
+			expected: []string{"a ", "b\t", "c"},
+		},
+		{
+			name:     "trailing only",
+			input:    "  a ,\tb\t, c\n",
+			trailing: true,
+			expected: []string{"  a", "\tb", " c"},
+		},
+		{
+			name:     "both",
+			input:    "  a b ,\t\t, c\r\n",
+			leading:  true,
+			trailing: true,
+			expected: []string{"a b", "", "c"},
+		},
+		{
+			name:     "whitespace inside quotes kept",
+			input:    "  \" a \"  ,\" \"\n",
+			leading:  true,
+			trailing: true,
+			expected: []string{" a ", " "},
+		},
+		{
+			name:     "space between closing quote and delimiter",
+			input:    "\"a\" ,b\n",
+			trailing: true,
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "space between closing quote and delimiter without trimming",
+			input:    "\"a\" ,b\n",
+			expected: []string{"a ", "b"},
+		},
+		{
+			name:     "tab delimiter is not whitespace",
+			input:    " a \t\t b \n",
+			leading:  true,
+			trailing: true,
+			comma:    ASCII_TAB,
+			expected: []string{"a", "", "b"},
+		},
+		{
+			name:       "custom whitespace set",
+			input:      "__a_ ,_\"b\"_\n",
+			leading:    true,
+			trailing:   true,
+			whitespace: "_",
+			expected:   []string{"a_ ", "b"},
+		},
+		{
+			name:     "final field at EOF",
+			input:    "a,  b  ",
+			leading:  true,
+			trailing: true,
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "strict accepts trimmed whitespace around quotes",
+			input:    " \"a\" , \"b\"\t\n",
+			leading:  true,
+			trailing: true,
+			strict:   true,
+			expected: []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.TrimLeadingSpace = tt.leading
+			reader.TrimTrailingSpace = tt.trailing
+			reader.Whitespace = tt.whitespace
+			reader.Strict = tt.strict
+			if tt.comma != 0 {
+				reader.Comma = tt.comma
+			}
+
+			result, err := reader.Read()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReader_Read_TrimSpaceStrictErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		leading  bool
+		trailing bool
+		expected error
+		column   int
+	}{
+		{
+			name:     "untrimmed space before opening quote",
+			input:    " \"a\",b\n",
+			trailing: true,
+			expected: ErrBareQuote,
+			column:   2,
+		},
+		{
+			name:     "untrimmed space after closing quote",
+			input:    "\"a\" ,b\n",
+			leading:  true,
+			expected: ErrQuote,
+			column:   4,
+		},
+		{
+			name:     "text after trimmed space",
+			input:    "\"a\"  x,b\n",
+			leading:  true,
+			trailing: true,
+			expected: ErrQuote,
+			column:   6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.TrimLeadingSpace = tt.leading
+			reader.TrimTrailingSpace = tt.trailing
+			reader.Strict = true
+
+			_, err := reader.Read()
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, err)
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Line != 1 || perr.Column != tt.column || perr.Offset != int64(tt.column-1) {
+				t.Errorf("Expected error at 1:%d, got %v", tt.column, err)
+			}
+		})
+	}
+}
+
+func TestReader_Read_Terminators(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		terminator Terminator
+		separator  byte
+		comma      byte
+		expected   [][]string
+		lines      []int // Line() after each read
+	}{
+		{
+			name:     "default keeps lone CR as data",
+			input:    "a\rb,c\r\nd\n",
+			expected: [][]string{{"a\rb", "c"}, {"d"}},
+			lines:    []int{1, 2},
+		},
+		{
+			name:       "LF only",
+			input:      "a,b\r\nc\n",
+			terminator: TermLF,
+			expected:   [][]string{{"a", "b\r"}, {"c"}},
+			lines:      []int{1, 2},
+		},
+		{
+			name:       "CRLF only",
+			input:      "a\nb,c\r\nd\re\r\n",
+			terminator: TermCRLF,
+			expected:   [][]string{{"a\nb", "c"}, {"d\re"}},
+			lines:      []int{1, 3},
+		},
+		{
+			name:       "lone CR",
+			input:      "a,b\rc,d\re\nf\r",
+			terminator: TermCR,
+			expected:   [][]string{{"a", "b"}, {"c", "d"}, {"e\nf"}},
+			lines:      []int{1, 2, 3},
+		},
+		{
+			name:       "any line break",
+			input:      "a\rb\nc\r\nd\r\re",
+			terminator: TermAny,
+			expected:   [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {""}, {"e"}},
+			lines:      []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:       "any line break inside quotes",
+			input:      "\"a\rb\r\nc\",d\re\n",
+			terminator: TermAny,
+			expected:   [][]string{{"a\rb\r\nc", "d"}, {"e"}},
+			lines:      []int{1, 4},
+		},
+		{
+			name:       "ASCII RS and US",
+			input:      "a\x1fb\x1fc\x1ed\ne\x1ff,g\x1e",
+			terminator: TermCustom,
+			separator:  ASCII_RS,
+			comma:      ASCII_US,
+			expected:   [][]string{{"a", "b", "c"}, {"d\ne", "f,g"}},
+			lines:      []int{1, 2},
+		},
+		{
+			name:       "custom terminator with quotes",
+			input:      "\"a;b\";c;\"d\x1e\"\x1e",
+			terminator: TermCustom,
+			separator:  ASCII_RS,
+			comma:      ';',
+			expected:   [][]string{{"a;b", "c", "d\x1e"}},
+			lines:      []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.Terminator = tt.terminator
+			reader.RecordSeparator = tt.separator
+			if tt.comma != 0 {
+				reader.Comma = tt.comma
+			}
+
+			for i, expected := range tt.expected {
+				result, err := reader.Read()
+				if err != nil {
+					t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+				}
+				if !reflect.DeepEqual(result, expected) {
+					t.Errorf("Read %d: expected %q, got %q", i+1, expected, result)
+				}
+				if line := reader.Line(); line != tt.lines[i] {
+					t.Errorf("Read %d: expected line %d, got %d", i+1, tt.lines[i], line)
+				}
+			}
+			if _, err := reader.Read(); err != io.EOF {
+				t.Errorf("Expected EOF error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReader_Read_TerminatorsStrictAndComments(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("# note\r\"a\"\r\"b\"x\r")))
+	reader.Terminator = TermCR
+	reader.Comment = '#'
+	reader.Strict = true
+
+	result, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"a"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if line := reader.Line(); line != 2 {
+		t.Errorf("Expected line 2, got %d", line)
+	}
+
+	_, err = reader.Read()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Err != ErrQuote || perr.Line != 3 || perr.Column != 4 {
+		t.Errorf("Expected ErrQuote at 3:4, got %v", err)
+	}
+
+	// A lone LF is data under TermCRLF; the error points at it, on the
+	// line of the closing quote
+	reader = NewReader(bufio.NewReader(strings.NewReader("\"a\"\nb\r\n")))
+	reader.Terminator = TermCRLF
+	reader.Strict = true
+
+	_, err = reader.Read()
+	expected := ParseError{StartLine: 1, Line: 1, Column: 4, Offset: 3, Err: ErrQuote}
+	if !errors.As(err, &perr) || *perr != expected {
+		t.Errorf("Expected %+v, got %v", expected, err)
+	}
+}
+
+func TestReader_Read_InvalidTerminator(t *testing.T) {
+	tests := []struct {
+		name       string
+		terminator Terminator
+		separator  byte
+	}{
+		{name: "unknown policy", terminator: Terminator(42)},
+		{name: "custom without separator", terminator: TermCustom},
+		{name: "custom equal to delimiter", terminator: TermCustom, separator: ','},
+		{name: "custom equal to quote", terminator: TermCustom, separator: ASCII_DQ},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader("a,b\n")))
+			reader.Terminator = tt.terminator
+			reader.RecordSeparator = tt.separator
+
+			if _, err := reader.Read(); err != ErrInvalidTerminator {
+				t.Errorf("Expected ErrInvalidTerminator, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReader_Read_Escape(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		quote    byte
+		expected []string
+		nulls    []bool
+	}{
+		{
+			name:     "escaped delimiter and quote",
+			input:    "a\\,b,\\\"c\\\",d\\\\\n",
+			quote:    ASCII_DQ,
+			expected: []string{"a,b", "\"c\"", "d\\"},
+			nulls:    []bool{false, false, false},
+		},
+		{
+			name:     "control sequences",
+			input:    "\\n\\r\\t\\0\\b\\Z,x\\qy\n",
+			quote:    ASCII_DQ,
+			expected: []string{"\n\r\t\x00\b\x1a", "xqy"},
+			nulls:    []bool{false, false},
+		},
+		{
+			name:     "escaped line break",
+			input:    "a\\\nb,c\n",
+			quote:    ASCII_DQ,
+			expected: []string{"a\nb", "c"},
+			nulls:    []bool{false, false},
+		},
+		{
+			name:     "escapes inside quotes coexist with doubling",
+			input:    "\"a\\\"b\"\"c\",\"\\\\\"\n",
+			quote:    ASCII_DQ,
+			expected: []string{"a\"b\"c", "\\"},
+			nulls:    []bool{false, false},
+		},
+		{
+			name:     "null marker",
+			input:    "1,\\N,\\N\n",
+			quote:    ASCII_DQ,
+			expected: []string{"1", "", ""},
+			nulls:    []bool{false, true, true},
+		},
+		{
+			name:     "null marker only as whole unquoted field",
+			input:    "\\Nx,\"\\N\",\\\\N,x\\N\n",
+			quote:    ASCII_DQ,
+			expected: []string{"Nx", "N", "\\N", "xN"},
+			nulls:    []bool{false, false, false, false},
+		},
+		{
+			name:     "escape only without quoting",
+			input:    "\"a\",b\\,c,\\N\n",
+			quote:    0,
+			expected: []string{"\"a\"", "b,c", ""},
+			nulls:    []bool{false, false, true},
+		},
+		{
+			name:     "escape at EOF",
+			input:    "a,b\\",
+			quote:    ASCII_DQ,
+			expected: []string{"a", "b\\"},
+			nulls:    []bool{false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.Escape = '\\'
+			reader.Quote = tt.quote
+
+			result, err := reader.Read()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+			for i, null := range tt.nulls {
+				if got := reader.IsNull(i); got != null {
+					t.Errorf("Field %d: expected IsNull %v, got %v", i, null, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReader_Read_EscapeTrimAndStrict(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader(" a\\ , \\N \nb\\\"c,\"d\"\n")))
+	reader.Escape = '\\'
+	reader.TrimLeadingSpace = true
+	reader.TrimTrailingSpace = true
+	reader.Strict = true
+
+	result, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Escaped whitespace is never trimmed
+	if expected := []string{"a ", ""}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if !reader.IsNull(1) {
+		t.Error("Expected field 1 to be null")
+	}
+
+	// An escaped quote is not a bare quote
+	result, err = reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"b\"c", "d"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestReader_Read_InvalidEscape(t *testing.T) {
+	for _, escape := range []byte{',', ASCII_DQ, ASCII_LF, ASCII_CR} {
+		reader := NewReader(bufio.NewReader(strings.NewReader("a,b\n")))
+		reader.Escape = escape
+
+		if _, err := reader.Read(); err != ErrInvalidEscape {
+			t.Errorf("Escape %q: expected ErrInvalidEscape, got %v", escape, err)
+		}
+	}
+}
+
+func TestReader_Read_LazyQuotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		trim     bool
+		expected [][]string
+	}{
+		{
+			name:     "bare quote in unquoted field",
+			input:    "a\"b,c\n",
+			expected: [][]string{{"a\"b", "c"}},
+		},
+		{
+			name:     "bare quotes do not swallow delimiters and line breaks",
+			input:    "12\" pipe,x\"y\nz,w\n",
+			expected: [][]string{{"12\" pipe", "x\"y"}, {"z", "w"}},
+		},
+		{
+			name:     "stray quote inside quoted field",
+			input:    "\"a\"b\",c\n",
+			expected: [][]string{{"a\"b", "c"}},
+		},
+		{
+			name:     "doubled quote inside quoted field",
+			input:    "\"a\"\"b\",c\n",
+			expected: [][]string{{"a\"b", "c"}},
+		},
+		{
+			name:     "quoted field closed at EOF",
+			input:    "a,\"b\"",
+			expected: [][]string{{"a", "b"}},
+		},
+		{
+			name:     "stray quote before line break inside quoted field",
+			input:    "\"a \"b\nc\",d\n",
+			expected: [][]string{{"a \"b\nc", "d"}},
+		},
+		{
+			name:     "closing quote followed by trimmed whitespace",
+			input:    "\"a\"  ,b\n",
+			trim:     true,
+			expected: [][]string{{"a", "b"}},
+		},
+		{
+			name:     "closing quote followed by untrimmed whitespace",
+			input:    "\"a\" ,b\"\n",
+			expected: [][]string{{"a\" ,b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+			reader.LazyQuotes = true
+			reader.TrimTrailingSpace = tt.trim
+
+			for i, expected := range tt.expected {
+				result, err := reader.Read()
+				if err != nil {
+					t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+				}
+				if !reflect.DeepEqual(result, expected) {
+					t.Errorf("Read %d: expected %q, got %q", i+1, expected, result)
+				}
+			}
+			if _, err := reader.Read(); err != io.EOF {
+				t.Errorf("Expected EOF error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReader_Read_QuoteModes(t *testing.T) {
+	input := "a\"b,c\n"
+
+	// Default mode toggles quoting at every quote, so the bare quote
+	// opens a field that is never closed
+	reader := NewReader(bufio.NewReader(strings.NewReader(input)))
+	if _, err := reader.Read(); !errors.Is(err, ErrUnterminatedQuote) {
+		t.Errorf("Default: expected ErrUnterminatedQuote, got %v", err)
+	}
+
+	// Strict mode rejects the bare quote
+	reader = NewReader(bufio.NewReader(strings.NewReader(input)))
+	reader.Strict = true
+	if _, err := reader.Read(); !errors.Is(err, ErrBareQuote) {
+		t.Errorf("Strict: expected ErrBareQuote, got %v", err)
+	}
+
+	// Lazy mode keeps it as data
+	reader = NewReader(bufio.NewReader(strings.NewReader(input)))
+	reader.LazyQuotes = true
+	result, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"a\"b", "c"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Lazy: expected %q, got %q", expected, result)
+	}
+
+	// Both at once is a configuration error
+	reader = NewReader(bufio.NewReader(strings.NewReader(input)))
+	reader.Strict = true
+	reader.LazyQuotes = true
+	if _, err := reader.Read(); err != ErrInvalidQuoteMode {
+		t.Errorf("Strict and lazy: expected ErrInvalidQuoteMode, got %v", err)
+	}
+}
+
+func TestReader_Read_ReuseRecord(t *testing.T) {
+	input := "a,b,c\nd,e\nf,g,h,i\n"
+	expected := [][]string{{"a", "b", "c"}, {"d", "e"}, {"f", "g", "h", "i"}}
+
+	reader := NewReader(bufio.NewReader(strings.NewReader(input)))
+	reader.ReuseRecord = true
+
+	var first []string
+	for i, want := range expected {
+		result, err := reader.Read()
+		if err != nil {
+			t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(result, want) {
+			t.Errorf("Read %d: expected %q, got %q", i+1, want, result)
+		}
+		if i == 0 {
+			first = result
+		} else if i == 1 && &result[0] != &first[0] {
+			t.Error("Expected the second record to reuse the first record's backing array")
+		}
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("Expected EOF error, got %v", err)
+	}
+}
+
+func TestReader_Read_NoReuseRecord(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("a,b\nc,d\n")))
+
+	first, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(first, expected) {
+		t.Errorf("Expected first record to stay %q, got %q", expected, first)
+	}
+}
+
+func TestReader_ReadBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected [][]string
+	}{
+		{
+			name:     "simple records",
+			input:    "a,bb,\nccc,d",
+			expected: [][]string{{"a", "bb", ""}, {"ccc", "d"}},
+		},
+		{
+			name:     "quoted and multiline fields",
+			input:    "\"a,b\",\"c\"\"d\",\"e\nf\"\r\n",
+			expected: [][]string{{"a,b", "c\"d", "e\nf"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(tt.input)))
+
+			for i, expected := range tt.expected {
+				result, err := reader.ReadBytes()
+				if err != nil {
+					t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+				}
+				got := make([]string, len(result))
+				for j, field := range result {
+					got[j] = string(field)
+				}
+				if !reflect.DeepEqual(got, expected) {
+					t.Errorf("Read %d: expected %q, got %q", i+1, expected, got)
+				}
+			}
+
+			result, err := reader.ReadBytes()
+			if err != io.EOF {
+				t.Errorf("Expected EOF error, got %v", err)
+			}
+			if result != nil {
+				t.Errorf("Expected nil result after EOF, got %q", result)
+			}
+		})
+	}
+}
+
+func TestReader_ReadBytes_Aliasing(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("abc,de\nxyz,uv\n")))
+
+	first, err := reader.ReadBytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Appending to a field must not overwrite the next one
+	_ = append(first[0], '!')
+	if string(first[1]) != "de" {
+		t.Errorf("Expected second field %q, got %q", "de", first[1])
+	}
+
+	kept := string(first[0])
+	second, err := reader.ReadBytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The next call reuses the buffer and the record slice
+	if &second[0] != &first[0] {
+		t.Error("Expected ReadBytes to reuse the record slice")
+	}
+	if string(first[0]) != "xyz" {
+		t.Errorf("Expected aliased field to be overwritten with %q, got %q", "xyz", first[0])
+	}
+	if kept != "abc" {
+		t.Errorf("Expected copied field %q, got %q", "abc", kept)
+	}
+}
+
+func TestReader_ReadBytes_Options(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("a , b\nc\n")))
+	reader.TrimLeadingSpace = true
+	reader.TrimTrailingSpace = true
+	reader.FieldsPerRecord = 0
+	reader.RaggedRows = RaggedPad
+
+	for i, expected := range [][]string{{"a", "b"}, {"c", ""}} {
+		result, err := reader.ReadBytes()
+		if err != nil {
+			t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+		}
+		if len(result) != len(expected) {
+			t.Fatalf("Read %d: expected %d fields, got %d", i+1, len(expected), len(result))
+		}
+		for j := range expected {
+			if string(result[j]) != expected[j] {
+				t.Errorf("Read %d, field %d: expected %q, got %q", i+1, j, expected[j], result[j])
+			}
+		}
+	}
+}
+
+func TestReader_Read_SingleAllocationPerRecord(t *testing.T) {
+	input := strings.Repeat("alpha,beta,\"gamma, delta\",epsilon\n", 200)
+	reader := NewReader(bufio.NewReader(strings.NewReader(input)))
+	reader.ReuseRecord = true
+
+	// Warm up the reusable buffers
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := reader.Read(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	if allocs != 1 {
+		t.Errorf("Expected 1 allocation per record, got %v", allocs)
+	}
+}
+
+func TestReader_Read_BufferBoundaries(t *testing.T) {
+	input := "id,name,notes\n" +
+		"1,\"Smith, John\",\"line one\nline two\"\r\n" +
+		"2,plain value with spaces,\"quote \"\" inside\"\n" +
+		"3,,\"" + strings.Repeat("long quoted ", 20) + "\"\n" +
+		"4," + strings.Repeat("unquoted", 30) + ",end"
+
+	// bufio.Reader enforces a minimum size of 16 bytes
+	type record struct {
+		fields    []string
+		positions [][2]int
+		line      int
+		offset    int64
+	}
+	parse := func(size int) []record {
+		reader := NewReader(bufio.NewReaderSize(strings.NewReader(input), size))
+		var records []record
+		for {
+			fields, err := reader.Read()
+			if err == io.EOF {
+				return records
+			}
+			if err != nil {
+				t.Fatalf("Buffer size %d: unexpected error: %v", size, err)
+			}
+			rec := record{fields: fields, line: reader.Line(), offset: reader.InputOffset()}
+			for i := range fields {
+				line, col := reader.FieldPos(i)
+				rec.positions = append(rec.positions, [2]int{line, col})
+			}
+			records = append(records, rec)
+		}
+	}
+
+	expected := parse(4096)
+	if len(expected) != 5 {
+		t.Fatalf("Expected 5 records, got %d", len(expected))
+	}
+	if got := expected[1].fields[2]; got != "line one\nline two" {
+		t.Errorf("Expected multiline field, got %q", got)
+	}
+	if got := expected[4].positions[2]; got != [2]int{6, 244} {
+		t.Errorf("Expected last field at 6:244, got %v", got)
+	}
+
+	for _, size := range []int{16, 17, 31, 64} {
+		if got := parse(size); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Buffer size %d: expected %+v, got %+v", size, expected, got)
+		}
+	}
+}
+
+func TestReader_Read_BufferBoundariesWithOptions(t *testing.T) {
+	input := "  a\\,b  ,\"x\ry\r\nz\" ,\\N\r" +
+		"\"" + strings.Repeat(`q\"`, 15) + "\"," + strings.Repeat("w ", 20) + "\n" +
+		"last"
+
+	parse := func(size int) [][]string {
+		reader := NewReader(bufio.NewReaderSize(strings.NewReader(input), size))
+		reader.Escape = '\\'
+		reader.Terminator = TermAny
+		reader.TrimLeadingSpace = true
+		reader.TrimTrailingSpace = true
+
+		var records [][]string
+		for {
+			fields, err := reader.Read()
+			if err == io.EOF {
+				return records
+			}
+			if err != nil {
+				t.Fatalf("Buffer size %d: unexpected error: %v", size, err)
+			}
+			for i := range fields {
+				line, col := reader.FieldPos(i)
+				fields[i] = fmt.Sprintf("%d:%d:%s", line, col, fields[i])
+			}
+			records = append(records, fields)
+		}
+	}
+
+	expected := [][]string{
+		{"1:1:a,b", "1:10:x\ry\r\nz", "3:5:"},
+		{"4:1:" + strings.Repeat(`q"`, 15), "4:49:" + strings.TrimSpace(strings.Repeat("w ", 20))},
+		{"5:1:last"},
+	}
+	for _, size := range []int{16, 19, 4096} {
+		if got := parse(size); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Buffer size %d: expected %q, got %q", size, expected, got)
+		}
+	}
+}
+
+func TestReader_PlainPrefix_SWAR(t *testing.T) {
+	configs := []struct {
+		name                       string
+		comma, quote, escape, term byte
+	}{
+		{"default", ',', '"', 0, ASCII_LF},
+		{"escape and custom terminator", ';', '\'', '\\', ASCII_RS},
+		{"no quote", ASCII_TAB, 0, 0, ASCII_CR},
+	}
+
+	for _, config := range configs {
+		reader := NewReader(bufio.NewReader(strings.NewReader("")))
+		reader.setSpecial(config.comma, config.quote, config.escape, config.term)
+
+		for c := 0; c < 256; c++ {
+			for size := 0; size <= swarMinRun+24; size++ {
+				for pos := 0; pos < size; pos++ {
+					// A special byte after pos must not hide the one at pos
+					buf := []byte(strings.Repeat("x", size))
+					buf[size-1] = config.comma
+					buf[pos] = byte(c)
+
+					expected := 0
+					for expected < size && !reader.special[buf[expected]] {
+						expected++
+					}
+					if got := reader.plainPrefix(buf); got != expected {
+						t.Fatalf("%s: byte %#x at %d of %d: expected %d, got %d",
+							config.name, c, pos, size, expected, got)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSwarScanner_Mask(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("")))
+	reader.setSpecial(',', '"', 0, ASCII_LF)
+
+	// Only the comma is structural, not the byte after it
+	block := binary.LittleEndian.Uint64([]byte("ab,-cdef"))
+	if m := reader.scan.mask(block); m != 0x800000 {
+		t.Errorf("Expected mask %#x, got %#x", 0x800000, m)
+	}
+
+	configs := []struct {
+		name                       string
+		comma, quote, escape, term byte
+	}{
+		{"default", ',', '"', 0, ASCII_LF},
+		{"escape and custom terminator", ';', '\'', '\\', ASCII_RS},
+	}
+
+	for _, config := range configs {
+		reader.setSpecial(config.comma, config.quote, config.escape, config.term)
+
+		// Every byte value in every lane, next to structural bytes
+		for c := 0; c < 256; c++ {
+			for pos := 0; pos < 8; pos++ {
+				for _, fill := range []byte{'x', config.comma, config.quote, 0, 0xFF} {
+					buf := bytes.Repeat([]byte{fill}, 8)
+					buf[pos] = byte(c)
+
+					var expected uint64
+					for i, ch := range buf {
+						if reader.special[ch] {
+							expected |= 0x80 << (8 * i)
+						}
+					}
+					if m := reader.scan.mask(binary.LittleEndian.Uint64(buf)); m != expected {
+						t.Fatalf("%s: block %q: expected mask %#x, got %#x", config.name, buf, expected, m)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNewReaderFrom(t *testing.T) {
+	reader := NewReaderFrom(strings.NewReader("a,\"b,c\"\nd,e\n"))
+
+	expected := [][]string{{"a", "b,c"}, {"d", "e"}}
+	for i, want := range expected {
+		result, err := reader.Read()
+		if err != nil {
+			t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(result, want) {
+			t.Errorf("Read %d: expected %q, got %q", i+1, want, result)
+		}
+	}
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("Expected EOF error, got %v", err)
+	}
+}
+
+func TestNewReaderFrom_Options(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []Option
+		expected [][]string
+		err      error
+	}{
+		{
+			name:     "comma",
+			input:    "a;b\n",
+			opts:     []Option{WithComma(';')},
+			expected: [][]string{{"a", "b"}},
+		},
+		{
+			name:     "multi-byte delimiter",
+			input:    "a||b\n",
+			opts:     []Option{WithDelimiter("||")},
+			expected: [][]string{{"a", "b"}},
+		},
+		{
+			name:     "quote, comment and skipped lines",
+			input:    "preamble\n# note\n'a,b',c\n",
+			opts:     []Option{WithQuote('\''), WithComment('#'), WithSkipLines(1)},
+			expected: [][]string{{"a,b", "c"}},
+		},
+		{
+			name:     "escape and trimming",
+			input:    " a\\,b , c \n",
+			opts:     []Option{WithEscape('\\'), WithTrimSpace(true, true)},
+			expected: [][]string{{"a,b", "c"}},
+		},
+		{
+			name:     "custom terminator",
+			input:    "a\x1fb\x1ec\x1fd\x1e",
+			opts:     []Option{WithComma(ASCII_US), WithTerminator(TermCustom, ASCII_RS)},
+			expected: [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name:     "fields per record",
+			input:    "a,b\nc\n",
+			opts:     []Option{WithFieldsPerRecord(0, RaggedPad)},
+			expected: [][]string{{"a", "b"}, {"c", ""}},
+		},
+		{
+			name:     "empty is null",
+			input:    "a,,\"\"\n",
+			opts:     []Option{WithEmptyIsNull(), WithFieldsPerRecord(3, RaggedError)},
+			expected: [][]string{{"a", "", ""}},
+		},
+		{
+			name:     "lazy quotes",
+			input:    "a\"b,c\n",
+			opts:     []Option{WithLazyQuotes()},
+			expected: [][]string{{"a\"b", "c"}},
+		},
+		{
+			name:  "strict",
+			input: "a\"b,c\n",
+			opts:  []Option{WithStrict()},
+			err:   ErrBareQuote,
+		},
+		{
+			name:     "encoding",
+			input:    "caf\xE9;1\n",
+			opts:     []Option{WithEncoding(EncodingWindows1252), WithComma(';')},
+			expected: [][]string{{"café", "1"}},
+		},
+		{
+			name:  "limits",
+			input: "a,b,c\n",
+			opts:  []Option{WithLimits(0, 0, 2)},
+			err:   ErrTooLarge,
+		},
+		{
+			name:  "invalid configuration",
+			input: "a,b\n",
+			opts:  []Option{WithQuote(',')},
+			err:   ErrInvalidQuote,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewReaderFrom(strings.NewReader(test.input), test.opts...)
+
+			var records [][]string
+			for {
+				result, err := reader.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if test.err == nil || !errors.Is(err, test.err) {
+						t.Fatalf("Expected error %v, got %v", test.err, err)
+					}
+					return
+				}
+				records = append(records, result)
+			}
+
+			if test.err != nil {
+				t.Fatalf("Expected error %v, got none", test.err)
+			}
+			if !reflect.DeepEqual(records, test.expected) {
+				t.Errorf("Expected %q, got %q", test.expected, records)
+			}
+		})
+	}
+}
+
+func TestNewReaderFrom_BufferSize(t *testing.T) {
+	reader := NewReaderFrom(strings.NewReader("a,b\n"))
+	if size := reader.r.Size(); size != DefaultBufferSize {
+		t.Errorf("Expected default buffer size %d, got %d", DefaultBufferSize, size)
+	}
+
+	reader = NewReaderFrom(strings.NewReader("a,b\n"), WithBufferSize(64*1024))
+	if size := reader.r.Size(); size != 64*1024 {
+		t.Errorf("Expected buffer size %d, got %d", 64*1024, size)
+	}
+
+	// A bufio.Reader with a large enough buffer is used as is
+	buffered := bufio.NewReaderSize(strings.NewReader("a,b\n"), 8192)
+	if reader = NewReaderFrom(buffered); reader.r != buffered {
+		t.Error("Expected the bufio.Reader to be used directly")
+	}
+
+	// Fields longer than the buffer are still read whole
+	field := strings.Repeat("x", 100)
+	reader = NewReaderFrom(strings.NewReader(field+","+field+"\n"), WithBufferSize(16))
+	result, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{field, field}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestReader_Reset(t *testing.T) {
+	reader := NewReaderFrom(strings.NewReader("# comment\na,b\nc,d\n"),
+		WithComment('#'), WithFieldsPerRecord(0, RaggedError))
+
+	for i := 0; i < 2; i++ {
+		if _, err := reader.Read(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if reader.FieldsPerRecord != 2 {
+		t.Fatalf("Expected FieldsPerRecord to be set to 2, got %d", reader.FieldsPerRecord)
+	}
+
+	// The new input keeps the comment setting but learns its own field count
+	reader.Reset(strings.NewReader("x\n# comment\n\"y\nz\"\n"))
+	if reader.FieldsPerRecord != 0 {
+		t.Errorf("Expected FieldsPerRecord to be reset to 0, got %d", reader.FieldsPerRecord)
+	}
+	if line := reader.Line(); line != 0 {
+		t.Errorf("Expected line 0 before the first record, got %d", line)
+	}
+	if offset := reader.InputOffset(); offset != 0 {
+		t.Errorf("Expected offset 0 before the first record, got %d", offset)
+	}
+
+	expected := []struct {
+		record []string
+		line   int
+	}{
+		{[]string{"x"}, 1},
+		{[]string{"y\nz"}, 3},
+	}
+	for i, want := range expected {
+		result, err := reader.Read()
+		if err != nil {
+			t.Fatalf("Unexpected error on read %d: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(result, want.record) {
+			t.Errorf("Read %d: expected %q, got %q", i+1, want.record, result)
+		}
+		if line := reader.Line(); line != want.line {
+			t.Errorf("Read %d: expected line %d, got %d", i+1, want.line, line)
+		}
+	}
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("Expected EOF error, got %v", err)
+	}
+	if offset := reader.InputOffset(); offset != 18 {
+		t.Errorf("Expected offset 18, got %d", offset)
+	}
+}
+
+func TestReader_Reset_KeepsCallerBuffer(t *testing.T) {
+	buffered := bufio.NewReaderSize(strings.NewReader("a,b\nc,d\n"), 16)
+	reader := NewReader(buffered)
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reader.Reset(strings.NewReader("x,y\n"))
+	result, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"x", "y"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	// The caller's bufio.Reader still holds the rest of its input
+	rest, err := io.ReadAll(buffered)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(rest) != "c,d\n" {
+		t.Errorf("Expected the caller's reader to keep %q, got %q", "c,d\n", rest)
+	}
+}
+
+func TestReader_Reset_Allocations(t *testing.T) {
+	input := "a,b,c\nd,e,f\n"
+	src := strings.NewReader(input)
+	reader := NewReaderFrom(src, WithReuseRecord())
+
+	readAll := func() {
+		src.Reset(input)
+		reader.Reset(src)
+		for {
+			if _, err := reader.Read(); err != nil {
+				return
+			}
+		}
+	}
+	readAll()
+
+	// One string per record; the Reader and its buffers are reused
+	if allocs := testing.AllocsPerRun(100, readAll); allocs != 2 {
+		t.Errorf("Expected 2 allocations per input, got %v", allocs)
+	}
+}
+
+func TestReader_ReadRecord_QuotedAndNull(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		configure func(*Reader)
+		expected  [][]Field
+	}{
+		{
+			name:  "quoted flags without EmptyIsNull",
+			input: "a,,\"\"\n",
+			expected: [][]Field{{
+				{Value: "a"}, {Value: ""}, {Value: "", Quoted: true},
+			}},
+		},
+		{
+			name:      "EmptyIsNull",
+			input:     "a,,\"\"\n,\n",
+			configure: func(r *Reader) { r.EmptyIsNull = true },
+			expected: [][]Field{
+				{{Value: "a"}, {Value: "", Null: true}, {Value: "", Quoted: true}},
+				{{Value: "", Null: true}, {Value: "", Null: true}},
+			},
+		},
+		{
+			name:  "partly quoted field",
+			input: "a\"b\"c,\"x\"\n",
+			expected: [][]Field{{
+				{Value: "abc", Quoted: true}, {Value: "x", Quoted: true},
+			}},
+		},
+		{
+			name:  "trimmed fields",
+			input: " \"\" ,  \n",
+			configure: func(r *Reader) {
+				r.EmptyIsNull = true
+				r.TrimLeadingSpace = true
+				r.TrimTrailingSpace = true
+			},
+			expected: [][]Field{{
+				{Value: "", Quoted: true}, {Value: "", Null: true},
+			}},
+		},
+		{
+			name:  "escaped null marker",
+			input: "\\N,\"\",\"\\N\"\n",
+			configure: func(r *Reader) {
+				r.EmptyIsNull = true
+				r.Escape = '\\'
+			},
+			expected: [][]Field{{
+				{Value: "", Null: true}, {Value: "", Quoted: true}, {Value: "N", Quoted: true},
+			}},
+		},
+		{
+			name:  "padded fields are null",
+			input: "a,\"\"\n",
+			configure: func(r *Reader) {
+				r.EmptyIsNull = true
+				r.FieldsPerRecord = 3
+				r.RaggedRows = RaggedPad
+			},
+			expected: [][]Field{{
+				{Value: "a"}, {Value: "", Quoted: true}, {Value: "", Null: true},
+			}},
+		},
+		{
+			name:      "blank line",
+			input:     "\n",
+			configure: func(r *Reader) { r.EmptyIsNull = true },
+			expected:  [][]Field{{{Value: "", Null: true}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(test.input)))
+			if test.configure != nil {
+				test.configure(reader)
+			}
+
+			var records [][]Field
+			for {
+				record, err := reader.ReadRecord()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				for i, field := range record {
+					if reader.IsQuoted(i) != field.Quoted || reader.IsNull(i) != field.Null {
+						t.Errorf("Field %d: IsQuoted/IsNull disagree with %+v", i, field)
+					}
+				}
+				records = append(records, record)
+			}
+
+			if !reflect.DeepEqual(records, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, records)
+			}
+		})
+	}
+}
+
+func TestReader_ReadRecord_ReuseRecord(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("a,\"b\"\nc,d\n")))
+	reader.ReuseRecord = true
+
+	first, err := reader.ReadRecord()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := reader.ReadRecord()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if &first[0] != &second[0] {
+		t.Error("Expected the second record to reuse the first record's backing array")
+	}
+	if expected := []Field{{Value: "c"}, {Value: "d"}}; !reflect.DeepEqual(second, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, second)
+	}
+}
+
+func TestReader_Read_Limits(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		configure func(*Reader)
+		expected  [][]string
+		line, col int
+	}{
+		{
+			name:      "field bytes",
+			input:     "abc,defgh\nabcdef,x\n",
+			configure: func(r *Reader) { r.MaxFieldBytes = 5 },
+			expected:  [][]string{{"abc", "defgh"}},
+			line:      2, col: 1,
+		},
+		{
+			name:      "field bytes count decoded quotes",
+			input:     "\"a\"\"b\",\"c\nd\"\n",
+			configure: func(r *Reader) { r.MaxFieldBytes = 3 },
+			expected:  [][]string{{"a\"b", "c\nd"}},
+		},
+		{
+			name:      "multiline field",
+			input:     "x,\"ab\ncdef\"\n",
+			configure: func(r *Reader) { r.MaxFieldBytes = 5 },
+			line:      1, col: 3,
+		},
+		{
+			name:      "record bytes",
+			input:     "abcd,efgh\nabcd,efghi\n",
+			configure: func(r *Reader) { r.MaxRecordBytes = 8 },
+			expected:  [][]string{{"abcd", "efgh"}},
+			line:      2, col: 6,
+		},
+		{
+			name:      "field count",
+			input:     "a,b,c\na,b,c,d\n",
+			configure: func(r *Reader) { r.MaxFields = 3 },
+			expected:  [][]string{{"a", "b", "c"}},
+			line:      2, col: 7,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(test.input)))
+			test.configure(reader)
+
+			var records [][]string
+			for {
+				result, err := reader.Read()
+				if err == io.EOF {
+					if test.line != 0 {
+						t.Fatal("Expected ErrTooLarge, got EOF")
+					}
+					break
+				}
+				if err != nil {
+					var pe *ParseError
+					if !errors.As(err, &pe) || !errors.Is(err, ErrTooLarge) {
+						t.Fatalf("Expected ParseError wrapping ErrTooLarge, got %v", err)
+					}
+					if pe.Line != test.line || pe.Column != test.col {
+						t.Errorf("Expected error at %d:%d, got %d:%d", test.line, test.col, pe.Line, pe.Column)
+					}
+					break
+				}
+				records = append(records, result)
+			}
+
+			if !reflect.DeepEqual(records, test.expected) {
+				t.Errorf("Expected %q, got %q", test.expected, records)
+			}
+		})
+	}
+}
+
+func TestReader_Read_LimitsFailFast(t *testing.T) {
+	// An unterminated quote must not read the whole input
+	input := "a,\"" + strings.Repeat("x", 1<<20)
+	reader := NewReader(bufio.NewReader(strings.NewReader(input)))
+	reader.MaxFieldBytes = 100
+
+	_, err := reader.Read()
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Expected ErrTooLarge, got %v", err)
+	}
+	if offset := reader.InputOffset(); offset > 8192 {
+		t.Errorf("Expected to stop within a buffer of the limit, stopped at offset %d", offset)
+	}
+	if cap(reader.recordBuf) > 8192 {
+		t.Errorf("Expected the record buffer to stay small, got capacity %d", cap(reader.recordBuf))
+	}
+
+	// Many empty fields are bounded by MaxFields
+	reader = NewReader(bufio.NewReader(strings.NewReader(strings.Repeat(",", 1<<20))))
+	reader.MaxFields = 1000
+	if _, err := reader.Read(); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Expected ErrTooLarge, got %v", err)
+	}
+	if n := len(reader.parsing); n != 1001 {
+		t.Errorf("Expected to stop at field 1001, got %d", n)
+	}
+}
+
+func TestReader_Read_BlankLines(t *testing.T) {
+	type record struct {
+		fields []string
+		line   int
+		err    error
+	}
+	tests := []struct {
+		name      string
+		input     string
+		configure func(*Reader)
+		expected  []record
+	}{
+		{
+			name:  "keep by default",
+			input: "a\n\nb\n",
+			expected: []record{
+				{fields: []string{"a"}, line: 1},
+				{fields: []string{""}, line: 2},
+				{fields: []string{"b"}, line: 3},
+			},
+		},
+		{
+			name:      "skip",
+			input:     "\na\n\n\r\nb\n\n\n",
+			configure: func(r *Reader) { r.BlankLines = BlankSkip },
+			expected: []record{
+				{fields: []string{"a"}, line: 2},
+				{fields: []string{"b"}, line: 5},
+			},
+		},
+		{
+			name:  "skip with comments and a custom terminator",
+			input: "a\x1e\x1e#x\x1e\x1eb\x1fc",
+			configure: func(r *Reader) {
+				r.BlankLines = BlankSkip
+				r.Comment = '#'
+				r.Comma = ASCII_US
+				r.Terminator = TermCustom
+				r.RecordSeparator = ASCII_RS
+			},
+			expected: []record{
+				{fields: []string{"a"}, line: 1},
+				{fields: []string{"b", "c"}, line: 5},
+			},
+		},
+		{
+			name:      "whitespace and quoted blank lines are kept",
+			input:     " \n\"x\n\ny\"\n",
+			configure: func(r *Reader) { r.BlankLines = BlankSkip; r.TrimLeadingSpace = true },
+			expected: []record{
+				{fields: []string{""}, line: 1},
+				{fields: []string{"x\n\ny"}, line: 2},
+			},
+		},
+		{
+			name:      "error",
+			input:     "a\n\r\nb\n",
+			configure: func(r *Reader) { r.BlankLines = BlankError },
+			expected: []record{
+				{fields: []string{"a"}, line: 1},
+				{err: &ParseError{StartLine: 2, Line: 2, Column: 1, Offset: 2, Err: ErrBlankLine}},
+				{fields: []string{"b"}, line: 3},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(test.input)))
+			if test.configure != nil {
+				test.configure(reader)
+			}
+
+			for i, want := range test.expected {
+				fields, err := reader.Read()
+				if want.err != nil {
+					if !reflect.DeepEqual(err, want.err) {
+						t.Errorf("Read %d: expected error %v, got %v", i+1, want.err, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("Read %d: unexpected error: %v", i+1, err)
+				}
+				if !reflect.DeepEqual(fields, want.fields) || reader.Line() != want.line {
+					t.Errorf("Read %d: expected %q on line %d, got %q on line %d",
+						i+1, want.fields, want.line, fields, reader.Line())
+				}
+			}
+
+			if _, err := reader.Read(); err != io.EOF {
+				t.Errorf("Expected EOF error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReader_Read_BOM(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		configure func(*Reader)
+		expected  [][]string
+		bom       BOM
+		err       error
+	}{
+		{
+			name:     "UTF-8 BOM is stripped",
+			input:    "\xEF\xBB\xBFname,age\nbob,3\n",
+			expected: [][]string{{"name", "age"}, {"bob", "3"}},
+			bom:      BOMUTF8,
+		},
+		{
+			name:      "KeepBOM",
+			input:     "\xEF\xBB\xBFname,age\n",
+			configure: func(r *Reader) { r.KeepBOM = true },
+			expected:  [][]string{{"\xEF\xBB\xBFname", "age"}},
+			bom:       BOMUTF8,
+		},
+		{
+			name:     "no BOM",
+			input:    "a\n\xEF\xBB\xBFb\n",
+			expected: [][]string{{"a"}, {"\xEF\xBB\xBFb"}},
+			bom:      BOMNone,
+		},
+		{
+			name:      "BOM before a preamble",
+			input:     "\xEF\xBB\xBFexported\na\n",
+			configure: func(r *Reader) { r.SkipLines = 1 },
+			expected:  [][]string{{"a"}},
+			bom:       BOMUTF8,
+		},
+		{
+			name:  "BOM only",
+			input: "\xEF\xBB\xBF",
+			bom:   BOMUTF8,
+		},
+		{
+			name:  "UTF-16LE",
+			input: "\xFF\xFEa\x00,\x00b\x00\n\x00",
+			bom:   BOMUTF16LE,
+			err:   ErrUTF16,
+		},
+		{
+			name:  "UTF-16BE",
+			input: "\xFE\xFF\x00a\x00,\x00b\x00\n",
+			bom:   BOMUTF16BE,
+			err:   ErrUTF16,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewReader(bufio.NewReader(strings.NewReader(test.input)))
+			if test.configure != nil {
+				test.configure(reader)
+			}
+
+			var records [][]string
+			for {
+				result, err := reader.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if err != test.err {
+						t.Fatalf("Expected error %v, got %v", test.err, err)
+					}
+					// The error does not go away on the next call
+					if _, err := reader.Read(); err != test.err {
+						t.Errorf("Expected error %v again, got %v", test.err, err)
+					}
+					break
+				}
+				records = append(records, result)
+			}
+
+			if !reflect.DeepEqual(records, test.expected) {
+				t.Errorf("Expected %q, got %q", test.expected, records)
+			}
+			if bom := reader.BOM(); bom != test.bom {
+				t.Errorf("Expected BOM %v, got %v", test.bom, bom)
+			}
+		})
+	}
+}
+
+func TestReader_Read_BOMPositions(t *testing.T) {
+	reader := NewReader(bufio.NewReader(strings.NewReader("\xEF\xBB\xBFa,b\n")))
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Columns ignore the BOM, byte offsets count it
+	if line, col := reader.FieldPos(0); line != 1 || col != 1 {
+		t.Errorf("Expected first field at 1:1, got %d:%d", line, col)
+	}
+	if offset := reader.InputOffset(); offset != 7 {
+		t.Errorf("Expected offset 7, got %d", offset)
+	}
+
+	// Reset checks the next input again
+	reader.Reset(strings.NewReader("c\n"))
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bom := reader.BOM(); bom != BOMNone {
+		t.Errorf("Expected no BOM after Reset, got %v", bom)
+	}
+}
+
+// encodeUTF16 encodes s as UTF-16, optionally preceded by a byte order mark
+func encodeUTF16(s string, bigEndian, bom bool) string {
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	buf := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		if bigEndian {
+			buf = append(buf, byte(u>>8), byte(u))
+		} else {
+			buf = append(buf, byte(u), byte(u>>8))
+		}
+	}
+	return string(buf)
+}
+
+func TestReader_Read_Encoding(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		configure func(*Reader)
+		expected  [][]string
+		bom       BOM
+	}{
+		{
+			name:  "UTF-16LE with BOM, tab separated",
+			input: encodeUTF16("Name\tCity\r\nZoë\t\"Zürich\tZH\"\r\n", false, true),
+			configure: func(r *Reader) {
+				r.Encoding = EncodingUTF16LE
+				r.Comma = ASCII_TAB
+			},
+			expected: [][]string{{"Name", "City"}, {"Zoë", "Zürich\tZH"}},
+			bom:      BOMUTF16LE,
+		},
+		{
+			name:      "UTF-16BE without BOM",
+			input:     encodeUTF16("a,b\n", true, false),
+			configure: func(r *Reader) { r.Encoding = EncodingUTF16BE },
+			expected:  [][]string{{"a", "b"}},
+			bom:       BOMNone,
+		},
+		{
+			name:      "BOM overrides the byte order",
+			input:     encodeUTF16("a,b\n", true, true),
+			configure: func(r *Reader) { r.Encoding = EncodingUTF16LE },
+			expected:  [][]string{{"a", "b"}},
+			bom:       BOMUTF16BE,
+		},
+		{
+			name:  "KeepBOM",
+			input: encodeUTF16("a,b\n", false, true),
+			configure: func(r *Reader) {
+				r.Encoding = EncodingUTF16LE
+				r.KeepBOM = true
+			},
+			expected: [][]string{{"\uFEFFa", "b"}},
+			bom:      BOMUTF16LE,
+		},
+		{
+			name:      "surrogate pairs and invalid units",
+			input:     encodeUTF16("😀,x", false, false) + "\x00\xD8,\x00" + "\x41",
+			configure: func(r *Reader) { r.Encoding = EncodingUTF16LE },
+			expected:  [][]string{{"😀", "x�", "�"}},
+		},
+		{
+			name:      "Windows-1251",
+			input:     "\xC8\xEC\xFF;\xC3\xEE\xF0\xEE\xE4\r\n\xC8\xE2\xE0\xED;\"\xCC\xEE\xF1\xEA\xE2\xE0, \xA8\xEB\xEA\xE8\"\r\n",
+			configure: func(r *Reader) { r.Encoding = EncodingWindows1251; r.Comma = ';' },
+			expected:  [][]string{{"Имя", "Город"}, {"Иван", "Москва, Ёлки"}},
+		},
+		{
+			name:      "Windows-1252",
+			input:     "\x93Caf\xE9\x94,5\x80\n",
+			configure: func(r *Reader) { r.Encoding = EncodingWindows1252 },
+			expected:  [][]string{{"“Café”", "5€"}},
+		},
+		{
+			name:      "ISO-8859-1",
+			input:     "caf\xE9,\x80\xFF\n",
+			configure: func(r *Reader) { r.Encoding = EncodingISO88591 },
+			expected:  [][]string{{"café", "\u0080ÿ"}},
+		},
+		{
+			name:      "single-byte encodings do not strip a BOM",
+			input:     "\xEF\xBB\xBFa\n",
+			configure: func(r *Reader) { r.Encoding = EncodingISO88591 },
+			expected:  [][]string{{"ï»¿a"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Reading one byte at a time splits code units and pairs
+			for _, oneByte := range []bool{false, true} {
+				var src io.Reader = strings.NewReader(test.input)
+				if oneByte {
+					src = iotest.OneByteReader(src)
+				}
+				reader := NewReader(bufio.NewReader(src))
+				test.configure(reader)
+
+				var records [][]string
+				for {
+					result, err := reader.Read()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+					records = append(records, result)
+				}
+
+				if !reflect.DeepEqual(records, test.expected) {
+					t.Errorf("One byte reads %v: expected %q, got %q", oneByte, test.expected, records)
+				}
+				if bom := reader.BOM(); bom != test.bom {
+					t.Errorf("Expected BOM %v, got %v", test.bom, bom)
+				}
+			}
+		})
+	}
+}
+
+func TestReader_Read_EncodingPositionsAndReset(t *testing.T) {
+	reader := NewReaderFrom(strings.NewReader("\xE9t\xE9,x\n"), WithEncoding(EncodingISO88591))
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Positions count the decoded UTF-8 bytes
+	if line, col := reader.FieldPos(1); line != 1 || col != 7 {
+		t.Errorf("Expected second field at 1:7, got %d:%d", line, col)
+	}
+	if offset := reader.InputOffset(); offset != 8 {
+		t.Errorf("Expected offset 8, got %d", offset)
+	}
+
+	// The encoding is picked up again after Reset
+	reader.Encoding = EncodingUTF8
+	reader.Reset(strings.NewReader("\xC3\xA9,y\n"))
+	result, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"é", "y"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	reader.Encoding = Encoding(42)
+	reader.Reset(strings.NewReader("a\n"))
+	if _, err := reader.Read(); err != ErrInvalidEncoding {
+		t.Errorf("Expected ErrInvalidEncoding, got %v", err)
+	}
+}
+
+// encodeSingleByte encodes s in a single-byte encoding. Characters missing
+// from it are dropped.
+func encodeSingleByte(s string, enc Encoding) string {
+	var buf []byte
+	for _, r := range s {
+		for c := 0; c < 256; c++ {
+			var decoded rune
+			switch {
+			case c < 0x80:
+				decoded = rune(c)
+			case enc == EncodingWindows1251:
+				decoded = windows1251[c-0x80]
+			case enc == EncodingWindows1252 && c < 0xA0:
+				decoded = windows1252[c-0x80]
+			default:
+				decoded = rune(c)
+			}
+			if decoded == r {
+				buf = append(buf, byte(c))
+				break
+			}
+		}
+	}
+	return string(buf)
+}
+
+func TestDetectEncoding(t *testing.T) {
+	russian := "id;Имя;Город\n1;Иван;Москва\n2;Ольга;\"Санкт-Петербург\"\n3;Пётр;Новосибирск\n"
+	french := "id,nom,ville\n1,Hélène,Orléans\n2,François,Besançon\n3,Jérôme,Rouen\n"
+	quotes := "id,product,price\n1,“Café” beans,5 €\n2,Crème brûlée – large,7 €\n"
+
+	tests := []struct {
+		name          string
+		sample        string
+		expected      Encoding
+		minConfidence float64
+		maxConfidence float64
+	}{
+		{"empty", "", EncodingUTF8, 0, 0},
+		{"ASCII", "a,b,c\n1,2,3\n", EncodingUTF8, 1, 1},
+		{"UTF-8 BOM", bomUTF8 + "a,b\n", EncodingUTF8, 1, 1},
+		{"UTF-16LE BOM", "\xFF\xFEa\x00", EncodingUTF16LE, 1, 1},
+		{"UTF-16BE BOM", "\xFE\xFF\x00a", EncodingUTF16BE, 1, 1},
+		{"UTF-8", russian, EncodingUTF8, 0.99, 1},
+		{"UTF-8 cut off mid-character", russian[:9], EncodingUTF8, 0.7, 1},
+		{"UTF-8 with a stray invalid byte", french + "4,Zo\xEB,Nice\n", EncodingUTF8, 0.5, 0.99},
+		{"UTF-16LE", encodeUTF16(french, false, false), EncodingUTF16LE, 0.99, 1},
+		{"UTF-16BE", encodeUTF16(russian, true, false), EncodingUTF16BE, 0.99, 1},
+		{"UTF-16LE cut off in a pair", encodeUTF16("a,b\n😀,😀", false, false)[:16], EncodingUTF16LE, 0.5, 1},
+		{"Windows-1251", encodeSingleByte(russian, EncodingWindows1251), EncodingWindows1251, 0.8, 0.9},
+		{"Windows-1252", encodeSingleByte(quotes, EncodingWindows1252), EncodingWindows1252, 0.5, 0.9},
+		{"ISO-8859-1", encodeSingleByte(french, EncodingISO88591), EncodingWindows1252, 0.8, 0.9},
+		{"ISO-8859-1 with C1 controls", "caf\xE9\x81,\x8Dx\n", EncodingISO88591, 0.1, 0.9},
+		{"single-byte symbols only", "temp,20\xB0\n", EncodingWindows1252, 0.3, 0.3},
+		{"Windows-1252 euro prices", encodeSingleByte("item,price\nbook,5€\npen,2€\n", EncodingWindows1252), EncodingWindows1252, 0.3, 0.3},
+		{"Windows-1252 euro amount", encodeSingleByte("a,b\n5 €,x\n", EncodingWindows1252), EncodingWindows1252, 0.3, 0.3},
+		{"one-letter words only", encodeSingleByte("à,b\nà,c\n", EncodingWindows1252), EncodingWindows1252, 0.3, 0.3},
+		{"stray NUL byte", "a,b\x00\n" + strings.Repeat("c,d\n", 20), EncodingUTF8, 1, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			enc, confidence := DetectEncoding([]byte(test.sample))
+			if enc != test.expected {
+				t.Errorf("Expected encoding %v, got %v", test.expected, enc)
+			}
+			if confidence < test.minConfidence || confidence > test.maxConfidence {
+				t.Errorf("Expected confidence in [%v, %v], got %v", test.minConfidence, test.maxConfidence, confidence)
+			}
+		})
+	}
+}
+
+func TestDetectEncoding_Read(t *testing.T) {
+	expected := [][]string{{"id", "Имя", "Город"}, {"1", "Иван", "Москва"}}
+	text := "id;Имя;Город\n1;Иван;Москва\n"
+
+	for _, input := range []string{
+		text,
+		encodeUTF16(text, false, false),
+		encodeUTF16(text, true, true),
+		encodeSingleByte(text, EncodingWindows1251),
+	} {
+		// Detect on the buffered start of the input, then parse all of it
+		buffered := bufio.NewReader(strings.NewReader(input))
+		sample, _ := buffered.Peek(4096)
+		enc, _ := DetectEncoding(sample)
+
+		reader := NewReader(buffered)
+		reader.Comma = ';'
+		reader.Encoding = enc
+
+		var records [][]string
+		for {
+			result, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for %v: %v", enc, err)
+			}
+			records = append(records, result)
+		}
+		if !reflect.DeepEqual(records, expected) {
+			t.Errorf("Detected %v: expected %q, got %q", enc, expected, records)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package csvc
+
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DetectEncoding guesses the Encoding of sample, typically the first few
+// kilobytes of a file, and returns it with a confidence between 0 and 1.
+//
+// A byte order mark is decisive. Without one, UTF-16 is recognized by its
+// zero bytes falling on one side of the code units, then UTF-8 by the
+// validity of its multi-byte sequences. Anything else is taken to be a
+// single-byte code page: Windows-1251 when the words containing non-ASCII
+// letters are spelled entirely in them, as in Cyrillic text, and
+// Windows-1252 otherwise, or ISO-8859-1 when the sample holds C1 control
+// bytes that Windows-1252 leaves undefined. Only words of two or more
+// letters count, so a sample whose non-ASCII bytes are symbols such as
+// "€" or one-letter words is Windows-1252 with a confidence of 0.3. Since
+// every byte sequence is valid in a single-byte code page, these guesses
+// never reach a confidence of 1.
+//
+// ASCII-only samples read the same in all encodings but UTF-16 and are
+// reported as EncodingUTF8 with a confidence of 1. An empty sample gives
+// EncodingUTF8 with a confidence of 0. A multi-byte character cut off at
+// the end of the sample is ignored.
+func DetectEncoding(sample []byte) (Encoding, float64) {
+	switch {
+	case len(sample) == 0:
+		return EncodingUTF8, 0
+	case string(sample[:min(len(sample), len(bomUTF8))]) == bomUTF8:
+		return EncodingUTF8, 1
+	case utf16BOM(sample) == BOMUTF16LE:
+		return EncodingUTF16LE, 1
+	case utf16BOM(sample) == BOMUTF16BE:
+		return EncodingUTF16BE, 1
+	}
+
+	if enc, confidence := detectUTF16(sample); confidence > 0 {
+		return enc, confidence
+	}
+	if confidence := detectUTF8(sample); confidence > 0 {
+		return EncodingUTF8, confidence
+	}
+	return detectSingleByte(sample)
+}
+
+// evidence maps a count of supporting observations to a confidence that
+// approaches 1 as the count grows
+func evidence(n int, base float64) float64 {
+	return 1 - math.Pow(base, float64(n))
+}
+
+// detectUTF16 checks sample for UTF-16 without a byte order mark. ASCII
+// characters, including the delimiters and line breaks of any CSV file,
+// have a zero high byte, so the zero bytes of UTF-16 text almost all fall
+// at the same position within the code units. A confidence of 0 means
+// sample is not UTF-16.
+func detectUTF16(sample []byte) (Encoding, float64) {
+	var zeros [2]int // zero bytes at even and odd offsets
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			zeros[0]++
+		}
+		if sample[i+1] == 0 {
+			zeros[1]++
+		}
+	}
+
+	enc, high, low := EncodingUTF16LE, zeros[1], zeros[0]
+	if zeros[0] > zeros[1] {
+		enc, high, low = EncodingUTF16BE, zeros[0], zeros[1]
+	}
+	if 20*high < len(sample)/2 || 4*low > high {
+		return EncodingUTF8, 0
+	}
+
+	confidence := evidence(high, 0.5) * (1 - float64(low)/float64(high))
+	d := decoder{enc: enc}
+	if !validUTF16(&d, sample) {
+		confidence /= 2
+	}
+	return enc, confidence
+}
+
+// validUTF16 reports whether sample holds no unpaired surrogates, apart
+// from a high surrogate cut off at the end
+func validUTF16(d *decoder, sample []byte) bool {
+	for i := 0; i+1 < len(sample); i += 2 {
+		u := d.unit(sample[i:])
+		switch {
+		case u < 0xD800 || u > 0xDFFF:
+		case u >= 0xDC00:
+			return false
+		case i+3 >= len(sample):
+			return true
+		default:
+			if next := d.unit(sample[i+2:]); next < 0xDC00 || next > 0xDFFF {
+				return false
+			}
+			i += 2
+		}
+	}
+	return true
+}
+
+// detectUTF8 returns the confidence that sample is UTF-8, or 0 if it
+// cannot be. Stray invalid bytes are tolerated when valid multi-byte
+// sequences clearly outnumber them.
+func detectUTF8(sample []byte) float64 {
+	multi, invalid := 0, 0
+	for i := 0; i < len(sample); {
+		if sample[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(sample[i:])
+		switch {
+		case r != utf8.RuneError || size > 1:
+			multi++
+		case !utf8.FullRune(sample[i:]):
+			size = len(sample) - i // cut off at the end of the sample
+		default:
+			invalid++
+		}
+		i += size
+	}
+
+	switch {
+	case multi == 0 && invalid == 0:
+		return 1
+	case multi < 4*invalid:
+		return 0
+	}
+	return evidence(multi, 0.25) * (1 - float64(invalid)/float64(multi))
+}
+
+// detectSingleByte chooses between the single-byte code pages. In
+// Windows-1251 text nearly every word with a non-ASCII letter consists of
+// them alone, while the accented letters of Latin text sit among ASCII
+// letters. Words of one letter are left out, as they are all-non-ASCII
+// in both ("à", "в"), and so is the lone "€" of a price, which is the
+// letter "Ђ" in Windows-1251.
+func detectSingleByte(sample []byte) (Encoding, float64) {
+	n, m := 0, 0 // words with non-ASCII letters, and those with nothing else
+	letters, nonASCII := 0, 0
+	undefined := false // C1 control bytes not assigned in Windows-1252
+	endWord := func() {
+		if nonASCII > 0 && letters > 1 {
+			n++
+			if nonASCII == letters {
+				m++
+			}
+		}
+		letters, nonASCII = 0, 0
+	}
+
+	for _, c := range sample {
+		if 0x80 <= c && c < 0xA0 && windows1252[c-0x80] == rune(c) {
+			undefined = true
+		}
+		switch {
+		case c >= 0x80 && unicode.IsLetter(windows1251[c-0x80]):
+			letters++
+			nonASCII++
+			continue
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+			letters++
+			continue
+		}
+		endWord()
+	}
+	endWord()
+
+	if n > 0 && 2*m > n {
+		return EncodingWindows1251, 0.9 * evidence(n, 0.5) * (2*float64(m)/float64(n) - 1)
+	}
+
+	enc := EncodingWindows1252
+	if undefined {
+		enc = EncodingISO88591
+	}
+	if n == 0 {
+		// only symbols such as "°" or "€", or one-letter words, outside
+		// ASCII
+		return enc, 0.3
+	}
+	return enc, 0.9 * evidence(n, 0.5) * (1 - 2*float64(m)/float64(n))
+}
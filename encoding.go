@@ -0,0 +1,144 @@
+package csvc
+
+import (
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is the character set of the input. Input in any encoding other
+// than UTF-8 is transcoded to UTF-8 before parsing, so delimiters, quotes
+// and line breaks are recognized by character rather than by raw byte.
+type Encoding int
+
+const (
+	EncodingUTF8        Encoding = iota // No transcoding (default)
+	EncodingUTF16LE                     // UTF-16 little endian, as in Excel's "Unicode text"
+	EncodingUTF16BE                     // UTF-16 big endian
+	EncodingISO88591                    // ISO-8859-1 (Latin-1)
+	EncodingWindows1252                 // Windows-1252 (Western European)
+	EncodingWindows1251                 // Windows-1251 (Cyrillic)
+)
+
+// windows1252 maps the bytes 0x80-0x9F of Windows-1252; all other bytes
+// match ISO-8859-1. Unassigned bytes map to the C1 control of the same
+// value, as in the WHATWG Encoding Standard.
+var windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021, // 0x80
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F, // 0x88
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014, // 0x90
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178, // 0x98
+}
+
+// windows1251 maps the bytes 0x80-0xFF of Windows-1251
+var windows1251 = [128]rune{
+	0x0402, 0x0403, 0x201A, 0x0453, 0x201E, 0x2026, 0x2020, 0x2021, // 0x80
+	0x20AC, 0x2030, 0x0409, 0x2039, 0x040A, 0x040C, 0x040B, 0x040F, // 0x88
+	0x0452, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014, // 0x90
+	0x0098, 0x2122, 0x0459, 0x203A, 0x045A, 0x045C, 0x045B, 0x045F, // 0x98
+	0x00A0, 0x040E, 0x045E, 0x0408, 0x00A4, 0x0490, 0x00A6, 0x00A7, // 0xA0
+	0x0401, 0x00A9, 0x0404, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x0407, // 0xA8
+	0x00B0, 0x00B1, 0x0406, 0x0456, 0x0491, 0x00B5, 0x00B6, 0x00B7, // 0xB0
+	0x0451, 0x2116, 0x0454, 0x00BB, 0x0458, 0x0405, 0x0455, 0x0457, // 0xB8
+	0x0410, 0x0411, 0x0412, 0x0413, 0x0414, 0x0415, 0x0416, 0x0417, // 0xC0
+	0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E, 0x041F, // 0xC8
+	0x0420, 0x0421, 0x0422, 0x0423, 0x0424, 0x0425, 0x0426, 0x0427, // 0xD0
+	0x0428, 0x0429, 0x042A, 0x042B, 0x042C, 0x042D, 0x042E, 0x042F, // 0xD8
+	0x0430, 0x0431, 0x0432, 0x0433, 0x0434, 0x0435, 0x0436, 0x0437, // 0xE0
+	0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E, 0x043F, // 0xE8
+	0x0440, 0x0441, 0x0442, 0x0443, 0x0444, 0x0445, 0x0446, 0x0447, // 0xF0
+	0x0448, 0x0449, 0x044A, 0x044B, 0x044C, 0x044D, 0x044E, 0x044F, // 0xF8
+}
+
+// decoder is an io.Reader transcoding its source from enc to UTF-8
+type decoder struct {
+	src io.Reader
+	enc Encoding
+	err error // error returned by src, reported once out is drained
+
+	in  [2048]byte // raw input; a split UTF-16 unit or pair stays at the front
+	n   int        // bytes of in not decoded yet
+	buf []byte     // decoded output
+	out []byte     // part of buf not returned yet
+}
+
+// reset makes d transcode src from enc, keeping its buffers
+func (d *decoder) reset(src io.Reader, enc Encoding) {
+	d.src, d.enc, d.err = src, enc, nil
+	d.n = 0
+	d.out = nil
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		n, err := d.src.Read(d.in[d.n:])
+		d.n += n
+		if err != nil {
+			d.err = err
+		}
+		d.decode(d.err != nil)
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// decode converts the raw input to UTF-8. Incomplete UTF-16 units at the
+// end are kept for the next call, or replaced by U+FFFD at the end of the
+// input.
+func (d *decoder) decode(final bool) {
+	in := d.in[:d.n]
+	out := d.buf[:0]
+	switch d.enc {
+	case EncodingUTF16LE, EncodingUTF16BE:
+		for len(in) >= 2 {
+			r := d.unit(in)
+			size := 2
+			if utf16.IsSurrogate(r) {
+				if len(in) < 4 && !final {
+					break
+				}
+				high := r
+				r = utf8.RuneError
+				if len(in) >= 4 {
+					if pair := utf16.DecodeRune(high, d.unit(in[2:])); pair != utf8.RuneError {
+						r, size = pair, 4
+					}
+				}
+			}
+			out = utf8.AppendRune(out, r)
+			in = in[size:]
+		}
+		if final && len(in) > 0 {
+			out = utf8.AppendRune(out, utf8.RuneError)
+			in = in[len(in):]
+		}
+	default:
+		for _, c := range in {
+			switch {
+			case c < utf8.RuneSelf:
+				out = append(out, c)
+			case d.enc == EncodingWindows1251:
+				out = utf8.AppendRune(out, windows1251[c-0x80])
+			case d.enc == EncodingWindows1252 && c < 0xA0:
+				out = utf8.AppendRune(out, windows1252[c-0x80])
+			default:
+				out = utf8.AppendRune(out, rune(c))
+			}
+		}
+		in = in[len(in):]
+	}
+	d.n = copy(d.in[:], in)
+	d.buf, d.out = out, out
+}
+
+// unit returns the UTF-16 code unit at the start of in
+func (d *decoder) unit(in []byte) rune {
+	if d.enc == EncodingUTF16BE {
+		return rune(in[0])<<8 | rune(in[1])
+	}
+	return rune(in[1])<<8 | rune(in[0])
+}
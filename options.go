@@ -0,0 +1,166 @@
+package csvc
+
+import (
+	"bufio"
+	"io"
+)
+
+// DefaultBufferSize is the input buffer size used by NewReaderFrom when
+// WithBufferSize is not given
+const DefaultBufferSize = 4096
+
+// Option configures a Reader created by NewReaderFrom. Options set the
+// exported Reader fields of the same name, which can still be changed
+// afterwards; invalid combinations are reported by the first Read.
+type Option func(*Reader)
+
+// NewReaderFrom creates a CSV reader reading from r with the given
+// options applied on top of the NewReader defaults. r is wrapped in a
+// bufio.Reader unless it already is one with a large enough buffer.
+func NewReaderFrom(r io.Reader, opts ...Option) *Reader {
+	b := NewReader(nil)
+	b.bufferSize = DefaultBufferSize
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.r = bufio.NewReaderSize(r, b.bufferSize)
+	b.ownsBuffer = io.Reader(b.r) != r
+	return b
+}
+
+// WithBufferSize sets the size of the input buffer. Larger buffers mean
+// fewer reads for large sequential files; sizes below 16 are raised to
+// 16 by bufio.
+func WithBufferSize(size int) Option {
+	return func(b *Reader) {
+		b.bufferSize = size
+	}
+}
+
+// WithComma sets Comma, the single-byte field delimiter
+func WithComma(comma byte) Option {
+	return func(b *Reader) {
+		b.Comma = comma
+	}
+}
+
+// WithDelimiter sets Delimiter, a field delimiter of any length
+func WithDelimiter(delimiter string) Option {
+	return func(b *Reader) {
+		b.Delimiter = delimiter
+	}
+}
+
+// WithQuote sets Quote. A quote of 0 disables quoting.
+func WithQuote(quote byte) Option {
+	return func(b *Reader) {
+		b.Quote = quote
+	}
+}
+
+// WithComment sets Comment, the comment character
+func WithComment(comment byte) Option {
+	return func(b *Reader) {
+		b.Comment = comment
+	}
+}
+
+// WithEscape sets Escape, the escape character
+func WithEscape(escape byte) Option {
+	return func(b *Reader) {
+		b.Escape = escape
+	}
+}
+
+// WithStrict enables Strict RFC 4180 checking
+func WithStrict() Option {
+	return func(b *Reader) {
+		b.Strict = true
+	}
+}
+
+// WithLazyQuotes enables LazyQuotes
+func WithLazyQuotes() Option {
+	return func(b *Reader) {
+		b.LazyQuotes = true
+	}
+}
+
+// WithFieldsPerRecord sets FieldsPerRecord and the RaggedRows policy for
+// records not matching it
+func WithFieldsPerRecord(n int, policy RaggedPolicy) Option {
+	return func(b *Reader) {
+		b.FieldsPerRecord = n
+		b.RaggedRows = policy
+	}
+}
+
+// WithSkipLines sets SkipLines, the number of preamble lines to skip
+func WithSkipLines(n int) Option {
+	return func(b *Reader) {
+		b.SkipLines = n
+	}
+}
+
+// WithTrimSpace sets TrimLeadingSpace and TrimTrailingSpace
+func WithTrimSpace(leading, trailing bool) Option {
+	return func(b *Reader) {
+		b.TrimLeadingSpace = leading
+		b.TrimTrailingSpace = trailing
+	}
+}
+
+// WithTerminator sets the Terminator policy. The separator is only used
+// with TermCustom, where it becomes RecordSeparator.
+func WithTerminator(term Terminator, separator byte) Option {
+	return func(b *Reader) {
+		b.Terminator = term
+		b.RecordSeparator = separator
+	}
+}
+
+// WithReuseRecord enables ReuseRecord
+func WithReuseRecord() Option {
+	return func(b *Reader) {
+		b.ReuseRecord = true
+	}
+}
+
+// WithEmptyIsNull enables EmptyIsNull
+func WithEmptyIsNull() Option {
+	return func(b *Reader) {
+		b.EmptyIsNull = true
+	}
+}
+
+// WithLimits sets MaxFieldBytes, MaxRecordBytes and MaxFields. Zero
+// means no limit.
+func WithLimits(fieldBytes, recordBytes, fields int) Option {
+	return func(b *Reader) {
+		b.MaxFieldBytes = fieldBytes
+		b.MaxRecordBytes = recordBytes
+		b.MaxFields = fields
+	}
+}
+
+// WithBlankLines sets the BlankLines policy
+func WithBlankLines(policy BlankLinePolicy) Option {
+	return func(b *Reader) {
+		b.BlankLines = policy
+	}
+}
+
+// WithKeepBOM enables KeepBOM
+func WithKeepBOM() Option {
+	return func(b *Reader) {
+		b.KeepBOM = true
+	}
+}
+
+// WithEncoding sets Encoding, the character set the input is transcoded
+// from
+func WithEncoding(enc Encoding) Option {
+	return func(b *Reader) {
+		b.Encoding = enc
+	}
+}
@@ -0,0 +1,111 @@
+package csvc
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// SWAR ("SIMD within a register") scanning tests eight input bytes at a
+// time using plain 64-bit arithmetic, so it works on every platform
+// without assembly.
+const (
+	swarLow  = 0x7F7F7F7F7F7F7F7F // low seven bits of every byte
+	swarHigh = 0x8080808080808080 // high bit of every byte
+	swarOnes = 0x0101010101010101 // 0x01 in every byte
+
+	// swarMinRun is how many bytes of a run are checked one at a time
+	// before switching to the SWAR scanner
+	swarMinRun = 32
+)
+
+// swarScanner finds structural bytes (delimiter, quote, CR, LF and any
+// escape or terminator byte) in a buffer
+type swarScanner struct {
+	// each structural byte repeated in all eight lanes. Unused slots
+	// repeat the first pattern so they can be tested unconditionally.
+	patterns [6]uint64
+	extra    bool // more than four structural bytes
+}
+
+// set rebuilds the scanner from a table of structural bytes
+func (s *swarScanner) set(special *[256]bool) {
+	n := 0
+	for ch, ok := range special {
+		if ok && n < len(s.patterns) {
+			s.patterns[n] = swarOnes * uint64(ch)
+			n++
+		}
+	}
+	for i := n; i < len(s.patterns); i++ {
+		s.patterns[i] = s.patterns[0]
+	}
+	s.extra = n > 4
+}
+
+// nonZero returns a word with the high bit set in exactly the bytes of x
+// that are not zero. Only the low seven bits of each byte are added, so no
+// carry crosses into the next byte.
+func nonZero(x uint64) uint64 {
+	return (x&swarLow + swarLow) | x
+}
+
+// structural returns the structural bitmask of block for four patterns:
+// bit 8*i+7 is set when byte i matches any of them
+func structural(block, p0, p1, p2, p3 uint64) uint64 {
+	return ^(nonZero(block^p0) & nonZero(block^p1) &
+		nonZero(block^p2) & nonZero(block^p3)) & swarHigh
+}
+
+// mask returns the structural bitmask of an 8-byte block loaded in
+// little-endian order: bit 8*i+7 is set exactly when byte i is
+// structural, so the mask can be iterated bit by bit
+func (s *swarScanner) mask(block uint64) uint64 {
+	p := &s.patterns
+	m := structural(block, p[0], p[1], p[2], p[3])
+	if s.extra {
+		m |= structural(block, p[4], p[5], p[4], p[5])
+	}
+	return m
+}
+
+// index returns the number of leading bytes of buf that are not
+// structural. The tail shorter than a block is checked in special.
+func (s *swarScanner) index(buf []byte, special *[256]bool) int {
+	var i int
+	if s.extra {
+		i = s.indexExtra(buf)
+	} else {
+		i = s.index4(buf)
+	}
+	for i < len(buf) && !special[buf[i]] {
+		i++
+	}
+	return i
+}
+
+// index4 returns the number of leading bytes of buf that were checked
+// and found to be non-structural, for scanners with at most four
+// structural bytes. It stops at the first structural byte or before a
+// tail shorter than a block.
+func (s *swarScanner) index4(buf []byte) int {
+	// The patterns are loaded once so they stay in registers
+	p0, p1, p2, p3 := s.patterns[0], s.patterns[1], s.patterns[2], s.patterns[3]
+	i := 0
+	for ; len(buf)-i >= 8; i += 8 {
+		if m := structural(binary.LittleEndian.Uint64(buf[i:]), p0, p1, p2, p3); m != 0 {
+			return i + bits.TrailingZeros64(m)>>3
+		}
+	}
+	return i
+}
+
+// indexExtra is index4 for scanners with more than four structural bytes
+func (s *swarScanner) indexExtra(buf []byte) int {
+	i := 0
+	for ; len(buf)-i >= 8; i += 8 {
+		if m := s.mask(binary.LittleEndian.Uint64(buf[i:])); m != 0 {
+			return i + bits.TrailingZeros64(m)>>3
+		}
+	}
+	return i
+}